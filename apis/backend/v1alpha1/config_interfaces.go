@@ -0,0 +1,61 @@
+package v1alpha1
+
+import "fmt"
+
+// GetType returns the type of the backend, a ConfigMap when not specified
+func (r *Config) GetType() BackendType {
+	if r == nil || r.Spec.Type == "" {
+		return BackendTypeConfigMap
+	}
+	return r.Spec.Type
+}
+
+// Validate validates the backend config
+func (r *Config) Validate() error {
+	switch r.GetType() {
+	case BackendTypeConfigMap, BackendTypeSecret, BackendTypeLocal:
+		return nil
+	default:
+		return fmt.Errorf("unsupported backend type %q, expected one of %s, %s or %s",
+			r.Spec.Type, BackendTypeConfigMap, BackendTypeSecret, BackendTypeLocal)
+	}
+}
+
+// HasClusterConfig returns true when the backend config specifies how to connect to
+// the cluster, w/o it the cluster of the current kube context is used
+func (r *Config) HasClusterConfig() bool {
+	if r == nil {
+		return false
+	}
+	s := r.Spec
+	return s.Host != nil || s.Username != nil || s.Password != nil || s.Insecure != nil ||
+		s.TLSServerName != nil || s.ClientCertificate != nil || s.ClientKey != nil ||
+		s.ClusterCACertificate != nil || len(s.ConfigPaths) > 0 || s.ConfigPath != nil ||
+		s.ConfigContext != nil || s.ConfigContextAuthInfo != nil || s.ConfigContextCluster != nil ||
+		s.Token != nil || s.ProxyURL != nil
+}
+
+// UsesKubeConfig returns true when the cluster config is derived from kube config files
+func (r *Config) UsesKubeConfig() bool {
+	if r == nil {
+		return false
+	}
+	s := r.Spec
+	return len(s.ConfigPaths) > 0 || s.ConfigPath != nil || s.ConfigContext != nil ||
+		s.ConfigContextAuthInfo != nil || s.ConfigContextCluster != nil
+}
+
+// WithoutCredentials returns a copy of the backend config w/o the credentials to
+// authenticate to the cluster
+func (r *Config) WithoutCredentials() *Config {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	c.Spec.Username = nil
+	c.Spec.Password = nil
+	c.Spec.ClientCertificate = nil
+	c.Spec.ClientKey = nil
+	c.Spec.Token = nil
+	return &c
+}
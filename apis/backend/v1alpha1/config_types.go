@@ -6,7 +6,29 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// BackendType is the type of backend that stores the inventory
+type BackendType string
+
+const (
+	// BackendTypeConfigMap stores the inventory in a ConfigMap in the cluster
+	BackendTypeConfigMap BackendType = "configmap"
+	// BackendTypeSecret stores the inventory in a Secret in the cluster
+	BackendTypeSecret BackendType = "secret"
+	// BackendTypeLocal stores the inventory in a local file, for offline or development use
+	BackendTypeLocal BackendType = "local"
+)
+
 type ConfigSpec struct {
+	// Type of the backend that stores the inventory
+	// +kubebuilder:validation:Enum=configmap;secret;local
+	// +kubebuilder:default=configmap
+	Type BackendType `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Path of the file that stores the inventory with the local backend,
+	// relative to the package directory
+	// +kubebuilder:default=".kform/kform-inventory-state.yaml"
+	Path *string `json:"path,omitempty" yaml:"path,omitempty"`
+
 	// The hostname (in form of URI) of Kubernetes master.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MaxLength=64
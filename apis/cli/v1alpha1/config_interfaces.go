@@ -0,0 +1,50 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func BuildConfig() *Config {
+	return &Config{
+		APIVersion: APIVersion,
+		Kind:       ConfigKind,
+	}
+}
+
+// ParseConfig unmarshals the CLI config file and validates the config version
+func ParseConfig(b []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.APIVersion != APIVersion || cfg.Kind != ConfigKind {
+		return nil, fmt.Errorf("unsupported config file, expected %s %s, got: %s %s", APIVersion, ConfigKind, cfg.APIVersion, cfg.Kind)
+	}
+	if mirror := cfg.Spec.ProviderInstallation.GetFilesystemMirror(); mirror != nil && mirror.Path == "" {
+		return nil, fmt.Errorf("invalid config file, filesystemMirror requires a path")
+	}
+	return cfg, nil
+}
+
+// GetFilesystemMirror returns the filesystem mirror, nil when the providers are installed
+// from the registries
+func (r *ProviderInstallation) GetFilesystemMirror() *FilesystemMirror {
+	if r == nil {
+		return nil
+	}
+	return r.FilesystemMirror
+}
+
+// GetFilesystemMirrorPath returns the directory of the filesystem mirror, an empty string when
+// the providers are installed from the registries
+func (r *Config) GetFilesystemMirrorPath() string {
+	if r == nil {
+		return ""
+	}
+	if mirror := r.Spec.ProviderInstallation.GetFilesystemMirror(); mirror != nil {
+		return mirror.Path
+	}
+	return ""
+}
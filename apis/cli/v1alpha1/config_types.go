@@ -0,0 +1,29 @@
+package v1alpha1
+
+// Config is the configuration of the kform CLI, shared by all packages of the user.
+type Config struct {
+	APIVersion string     `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string     `json:"kind" yaml:"kind"`
+	Spec       ConfigSpec `json:"spec" yaml:"spec"`
+}
+
+type ConfigSpec struct {
+	// PluginCacheDir is the directory of the plugin cache shared by all packages,
+	// the KFORM_PLUGIN_CACHE_DIR env variable takes precedence
+	PluginCacheDir string `json:"pluginCacheDir,omitempty" yaml:"pluginCacheDir,omitempty"`
+	// ProviderInstallation configures where kform init installs the providers from
+	ProviderInstallation *ProviderInstallation `json:"providerInstallation,omitempty" yaml:"providerInstallation,omitempty"`
+}
+
+type ProviderInstallation struct {
+	// FilesystemMirror installs the providers from a local directory instead of the registries
+	FilesystemMirror *FilesystemMirror `json:"filesystemMirror,omitempty" yaml:"filesystemMirror,omitempty"`
+}
+
+// FilesystemMirror is a directory that mirrors the provider registries. A provider is located
+// in the mirror at <hostname>/<namespace>, either as an OCI image layout per platform
+// <name>_<os>_<arch> or as release archives <name>_<version>_<os>_<arch>.tar.gz
+type FilesystemMirror struct {
+	// Path is the directory of the mirror
+	Path string `json:"path" yaml:"path"`
+}
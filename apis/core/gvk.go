@@ -7,3 +7,9 @@ var ConfigMapGVK = schema.GroupVersionKind{
 	Kind:    "ConfigMap",
 	Version: "v1",
 }
+
+var SecretGVK = schema.GroupVersionKind{
+	Group:   "",
+	Kind:    "Secret",
+	Version: "v1",
+}
@@ -3,8 +3,11 @@ package v1alpha1
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
 	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -21,15 +24,114 @@ func (r Object) GetRnNode(blockType, resourceType, resourceID string) *yaml.RNod
 	annotations[kformv1alpha1.KformAnnotationKey_BLOCK_TYPE] = kformv1alpha1.BlockTYPE_DATA.String()
 	annotations[kformv1alpha1.KformAnnotationKey_RESOURCE_TYPE] = resourceType
 	annotations[kformv1alpha1.KformAnnotationKey_RESOURCE_ID] = resourceID
+	if len(r.Dependencies) != 0 {
+		annotations[kformv1alpha1.KformAnnotationKey_DEPENDS_ON] = strings.Join(r.Dependencies, ",")
+	}
 	rn.SetAnnotations(annotations)
 	return rn
 }
 
+// GetBlockInfos returns per package the info of each resource block
+func (r *Inventory) GetBlockInfos() map[string]map[string]*BlockInfo {
+	blockInfos := map[string]map[string]*BlockInfo{}
+	if r == nil {
+		return blockInfos
+	}
+	for pkgName, pkgInv := range r.Packages {
+		if pkgInv == nil {
+			continue
+		}
+		blockInfos[pkgName] = map[string]*BlockInfo{}
+		for blockName, objs := range pkgInv.PackageResources {
+			if len(objs) != 0 {
+				blockInfo := objs[0].BlockInfo
+				blockInfos[pkgName][blockName] = &blockInfo
+			}
+		}
+	}
+	return blockInfos
+}
+
+// GetResources returns per package the objects of each resource block, the
+// resources only contain the reference of the objects
+func (r *Inventory) GetResources() store.Storer[store.Storer[data.BlockData]] {
+	resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+	if r == nil {
+		return resources
+	}
+	for pkgName, pkgInv := range r.Packages {
+		if pkgInv == nil {
+			continue
+		}
+		pkgStore := memory.NewStore[data.BlockData](nil)
+		for blockName, objs := range pkgInv.PackageResources {
+			bd := data.BlockData{}
+			for _, obj := range objs {
+				rn := yaml.NewMapRNode(nil)
+				rn.SetApiVersion(schema.GroupVersion{Group: obj.ObjectRef.Group, Version: obj.ObjectRef.Version}.String())
+				rn.SetKind(obj.ObjectRef.Kind)
+				rn.SetName(obj.ObjectRef.Name)
+				rn.SetNamespace(obj.ObjectRef.Namespace)
+				bd = bd.Add(rn)
+			}
+			pkgStore.Create(store.ToKey(blockName), bd)
+		}
+		resources.Create(store.ToKey(pkgName), pkgStore)
+	}
+	return resources
+}
+
 func MarshalProviders(providers map[string]string) ([]byte, error) {
 	return yaml.Marshal(providers)
 }
 
-func MarshalPackages(ctx context.Context, pkgs store.Storer[store.Storer[data.BlockData]]) ([]byte, error) {
+// ObjectStatusFn returns the status of an object of a resource block of a package
+type ObjectStatusFn func(pkgName, blockName string, ref ObjectReference) ObjectStatus
+
+// GetObjectStatus returns the status of an object recorded in the inventory, nil safe
+func (r *Inventory) GetObjectStatus(pkgName, blockName string, ref ObjectReference) (ObjectStatus, bool) {
+	if r == nil || r.Packages[pkgName] == nil {
+		return ObjectStatus{}, false
+	}
+	for _, obj := range r.Packages[pkgName].PackageResources[blockName] {
+		if obj.ObjectRef == ref {
+			return obj.ObjectStatus, true
+		}
+	}
+	return ObjectStatus{}, false
+}
+
+// GetObjectReference returns the reference of the object
+func GetObjectReference(rn *yaml.RNode) (ObjectReference, error) {
+	gv, err := schema.ParseGroupVersion(rn.GetApiVersion())
+	if err != nil {
+		return ObjectReference{}, err
+	}
+	return ObjectReference{
+		Group:     gv.Group,
+		Version:   gv.Version,
+		Kind:      rn.GetKind(),
+		Name:      rn.GetName(),
+		Namespace: rn.GetNamespace(),
+	}, nil
+}
+
+// String returns the reference as <KIND>.<GROUP>/<NAMESPACE>/<NAME>
+func (r ObjectReference) String() string {
+	kind := r.Kind
+	if r.Group != "" {
+		kind = fmt.Sprintf("%s.%s", r.Kind, r.Group)
+	}
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s/%s", kind, r.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, r.Namespace, r.Name)
+}
+
+// MarshalPackages marshals the objects per package together with the info
+// of the resource block they belong to, blockInfos are keyed per package and block.
+// The status of the objects is provided by statusFn, when nil the status is not set.
+func MarshalPackages(ctx context.Context, pkgs store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*BlockInfo, statusFn ObjectStatusFn) ([]byte, error) {
 	packages := map[string]*PackageInventory{}
 	var errm error
 	pkgs.List(func(k store.Key, pkgStore store.Storer[data.BlockData]) {
@@ -38,11 +140,16 @@ func MarshalPackages(ctx context.Context, pkgs store.Storer[store.Storer[data.Bl
 			PackageResources: map[string][]Object{},
 		}
 		pkgStore.List(func(k store.Key, bd data.BlockData) {
-			objs, err := getObject(bd)
+			objs, err := getObject(bd, blockInfos[pkgName][k.Name])
 			if err != nil {
 				errors.Join(errm, err)
 				return
 			}
+			if statusFn != nil {
+				for i := range objs {
+					objs[i].ObjectStatus = statusFn(pkgName, k.Name, objs[i].ObjectRef)
+				}
+			}
 			packages[pkgName].PackageResources[k.Name] = objs
 		})
 	})
@@ -52,24 +159,37 @@ func MarshalPackages(ctx context.Context, pkgs store.Storer[store.Storer[data.Bl
 	return yaml.Marshal(packages)
 }
 
-func getObject(bd data.BlockData) ([]Object, error) {
+func getObject(bd data.BlockData, blockInfo *BlockInfo) ([]Object, error) {
 	rns := bd.Get()
 	objs := make([]Object, 0, len(rns))
+	if blockInfo == nil {
+		blockInfo = &BlockInfo{}
+	}
 	for _, rn := range rns {
-		apiVersion := rn.GetApiVersion()
-		gv, err := schema.ParseGroupVersion(apiVersion)
+		ref, err := GetObjectReference(rn)
 		if err != nil {
 			return objs, err
 		}
 		objs = append(objs, Object{
-			ObjectRef: ObjectReference{
-				Group:     gv.Group,
-				Version:   gv.Version,
-				Kind:      rn.GetKind(),
-				Name:      rn.GetName(),
-				Namespace: rn.GetNamespace(),
-			},
+			ObjectRef: ref,
+			BlockInfo: *blockInfo,
 		})
 	}
 	return objs, nil
 }
+
+// GetLifeCycle returns the lifecycle of the block, nil safe
+func (r *BlockInfo) GetLifeCycle() *kformv1alpha1.LifeCycle {
+	if r == nil {
+		return nil
+	}
+	return r.LifeCycle
+}
+
+// IsSensitive returns true when the values of the objects of the block are sensitive, nil safe
+func (r *BlockInfo) IsSensitive() bool {
+	if r == nil {
+		return false
+	}
+	return r.Sensitive
+}
@@ -1,5 +1,10 @@
 package v1alpha1
 
+import (
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // Non Goal: expose execution context
 // Goal
 // Expose the cluster resources that were applied to the system
@@ -16,12 +21,36 @@ type PackageInventory struct {
 
 type Object struct {
 	ObjectRef ObjectReference `json:"objectRef,omitempty" yaml:"objectRef,omitempty"`
+	// ObjectStatus is the status of the last actuation of the object
+	ObjectStatus `json:",inline" yaml:",inline"`
+	// BlockInfo is the information of the resource block the object belongs to
+	BlockInfo `json:",inline" yaml:",inline"`
+}
+
+// ObjectStatus is the status of the actuation and reconciliation of an object
+type ObjectStatus struct {
 	// Strategy indicates the method of actuation (apply or delete) used or planned to be used.
 	Strategy ActuationStrategy `json:"strategy,omitempty" yaml:"strategy,omitempty"`
 	// Actuation indicates whether actuation has been performed yet and how it went.
 	Actuation ActuationStatus `json:"actuation,omitempty" yaml:"actuation,omitempty"`
 	// Reconcile indicates whether reconciliation has been performed yet and how it went.
 	Reconcile ReconcileStatus `json:"reconcile,omitempty" yaml:"reconcile,omitempty"`
+	// ActuationTime is the time the last actuation of the object was performed.
+	ActuationTime *metav1.Time `json:"actuationTime,omitempty" yaml:"actuationTime,omitempty"`
+	// ReconcileTime is the time the last reconciliation of the object was observed.
+	ReconcileTime *metav1.Time `json:"reconcileTime,omitempty" yaml:"reconcileTime,omitempty"`
+}
+
+// BlockInfo is the information of a resource block which is retained with its objects,
+// such that the objects can be actuated w/o the package.
+type BlockInfo struct {
+	// Dependencies are the resource blocks <RESOURCE_TYPE>.<RESOURCE_ID> this block depends on.
+	// They are used to delete the objects in the reverse order of creation.
+	Dependencies []string `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	// LifeCycle is the lifecycle of the block
+	LifeCycle *kformv1alpha1.LifeCycle `json:"lifecycle,omitempty" yaml:"lifecycle,omitempty"`
+	// Sensitive indicates the values of the objects are masked when they are printed
+	Sensitive bool `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
 }
 
 // ObjectReference is a reference to a KRM resource by name and kind.
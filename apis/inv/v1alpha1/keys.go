@@ -11,3 +11,13 @@ const (
 	// InventoryOwnerKey is the annotation key indicating the inventory owning an object.
 	InventoryOwnerKey = "inv.kform.dev/inventory-owner"
 )
+
+const (
+	// InventoryEncodingKey is the annotation key indicating the encoding of the inventory
+	// data, when absent the inventory data is stored as is.
+	InventoryEncodingKey = "inv.kform.dev/inventory-encoding"
+
+	// InventoryShardsKey is the annotation key on the inventory index object listing the
+	// objects which hold the shards of the inventory data in order.
+	InventoryShardsKey = "inv.kform.dev/inventory-shards"
+)
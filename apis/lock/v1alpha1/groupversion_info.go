@@ -0,0 +1,8 @@
+package v1alpha1
+
+const (
+	Group      = "lock.kform.dev"
+	Version    = "v1alpha1"
+	APIVersion = Group + "/" + Version
+	LockKind   = "Lock"
+)
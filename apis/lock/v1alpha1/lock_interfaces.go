@@ -0,0 +1,57 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func BuildLock() *Lock {
+	return &Lock{
+		APIVersion: APIVersion,
+		Kind:       LockKind,
+		Spec: LockSpec{
+			Providers: map[string]*ProviderLock{},
+		},
+	}
+}
+
+// ParseLock unmarshals the lock file and validates the lock version
+func ParseLock(b []byte) (*Lock, error) {
+	lock := &Lock{}
+	if err := yaml.Unmarshal(b, lock); err != nil {
+		return nil, err
+	}
+	if lock.APIVersion != APIVersion || lock.Kind != LockKind {
+		return nil, fmt.Errorf("unsupported lock file, expected %s %s, got: %s %s", APIVersion, LockKind, lock.APIVersion, lock.Kind)
+	}
+	if lock.Spec.Providers == nil {
+		lock.Spec.Providers = map[string]*ProviderLock{}
+	}
+	for name, provider := range lock.Spec.Providers {
+		if provider == nil || provider.Source == "" || provider.Version == "" {
+			return nil, fmt.Errorf("invalid lock file, provider %s requires a source and version", name)
+		}
+	}
+	return lock, nil
+}
+
+func (r *Lock) Marshal() ([]byte, error) {
+	return yaml.Marshal(r)
+}
+
+// GetProvider returns the locked release of the provider, nil when the provider is not locked
+func (r *Lock) GetProvider(name string) *ProviderLock {
+	if r == nil {
+		return nil
+	}
+	return r.Spec.Providers[name]
+}
+
+// GetPlatform returns the checksums of the platform, nil when the platform is not locked
+func (r *ProviderLock) GetPlatform(platform string) *PlatformChecksums {
+	if r == nil {
+		return nil
+	}
+	return r.Platforms[platform]
+}
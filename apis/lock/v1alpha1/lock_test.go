@@ -0,0 +1,70 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLock(t *testing.T) {
+	cases := map[string]struct {
+		lock        string
+		expectedErr bool
+	}{
+		"Valid": {
+			lock: `apiVersion: lock.kform.dev/v1alpha1
+kind: Lock
+spec:
+  providers:
+    kubernetes:
+      source: europe-docker.pkg.dev/srlinux/eu.gcr.io
+      version: 0.0.2
+      platforms:
+        linux_amd64:
+          manifest: sha256:0a
+          binary: sha256:0b
+`,
+		},
+		"WrongKind": {
+			lock: `apiVersion: lock.kform.dev/v1alpha1
+kind: Plan
+`,
+			expectedErr: true,
+		},
+		"NoVersion": {
+			lock: `apiVersion: lock.kform.dev/v1alpha1
+kind: Lock
+spec:
+  providers:
+    kubernetes:
+      source: europe-docker.pkg.dev/srlinux/eu.gcr.io
+`,
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lock, err := ParseLock([]byte(tc.lock))
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			b, err := lock.Marshal()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.lock, string(b)); diff != "" {
+				t.Errorf("-want lock, +got:\n%s", diff)
+			}
+			if lock.GetProvider("kubernetes").GetPlatform("linux_amd64").Binary != "sha256:0b" {
+				t.Errorf("want binary checksum sha256:0b, got: %v", lock.GetProvider("kubernetes").GetPlatform("linux_amd64"))
+			}
+		})
+	}
+}
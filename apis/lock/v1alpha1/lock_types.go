@@ -0,0 +1,33 @@
+package v1alpha1
+
+// Lock records the provider releases selected by kform init, such that subsequent
+// runs use the same releases and can verify the installed provider binaries.
+type Lock struct {
+	APIVersion string   `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Spec       LockSpec `json:"spec" yaml:"spec"`
+}
+
+type LockSpec struct {
+	// Providers contains the locked release per provider
+	Providers map[string]*ProviderLock `json:"providers,omitempty" yaml:"providers,omitempty"`
+}
+
+type ProviderLock struct {
+	// Source is the <hostname>/<namespace> the provider is installed from
+	Source string `json:"source" yaml:"source"`
+	// Version is the selected version of the provider
+	Version string `json:"version" yaml:"version"`
+	// Constraints are the version constraints the version was selected with
+	Constraints string `json:"constraints,omitempty" yaml:"constraints,omitempty"`
+	// Platforms contains the checksums of the release per platform <os>_<arch>
+	Platforms map[string]*PlatformChecksums `json:"platforms,omitempty" yaml:"platforms,omitempty"`
+}
+
+type PlatformChecksums struct {
+	// Manifest is the digest of the OCI manifest of the release, empty when the release
+	// was installed from a release archive
+	Manifest string `json:"manifest" yaml:"manifest"`
+	// Binary is the sha256 checksum of the unpacked provider binary
+	Binary string `json:"binary" yaml:"binary"`
+}
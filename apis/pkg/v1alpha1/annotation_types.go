@@ -15,6 +15,7 @@ const (
 	KformAnnotationKey_DESCRIPTION   = KformAnnotationKeyPrefix + "/" + "description"
 	KformAnnotationKey_SENSITIVE     = KformAnnotationKeyPrefix + "/" + "sensitive"
 	KformAnnotationKey_LIFECYCLE     = KformAnnotationKeyPrefix + "/" + "lifecycle"
+	KformAnnotationKey_VALIDATION    = KformAnnotationKeyPrefix + "/" + "validation"
 	KformAnnotationKey_PRECONDITION  = KformAnnotationKeyPrefix + "/" + "pre-condition"
 	KformAnnotationKey_POSTCONDITION = KformAnnotationKeyPrefix + "/" + "post-condition"
 	KformAnnotationKey_PROVIDERS     = KformAnnotationKeyPrefix + "/" + "providers"
@@ -40,6 +41,7 @@ var KformAnnotations = []string{
 	KformAnnotationKey_DESCRIPTION,
 	KformAnnotationKey_SENSITIVE,
 	KformAnnotationKey_LIFECYCLE,
+	KformAnnotationKey_VALIDATION,
 	KformAnnotationKey_PRECONDITION,
 	KformAnnotationKey_POSTCONDITION,
 	KformAnnotationKey_PROVIDERS,
@@ -0,0 +1,14 @@
+package v1alpha1
+
+// SensitiveMask replaces the values of sensitive blocks wherever kform prints them
+const SensitiveMask = "***"
+
+// IsSensitive returns true when the values of the block are sensitive, either
+// because the block is annotated as sensitive or because its values are derived
+// from a sensitive block
+func (r *Attributes) IsSensitive() bool {
+	if r == nil {
+		return false
+	}
+	return r.Sensitive
+}
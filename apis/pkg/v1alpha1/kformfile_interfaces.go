@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	"fmt"
+
 	"github.com/apparentlymart/go-versions/versions"
 	"github.com/kform-dev/kform/pkg/syntax/address"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,12 +20,17 @@ func BuildKformFile(meta metav1.ObjectMeta, spec KformFileSpec) *KformFile {
 	}
 }
 
+// Validate validates the source and the version constraints of the provider requirement,
+// providers w/o source are local and the version constraints are optional
 func (r Provider) Validate() error {
 	if _, _, err := address.ParseSource(r.Source); err != nil {
-		return err
+		return fmt.Errorf("invalid source %q, err: %s", r.Source, err.Error())
+	}
+	if r.Version == "" {
+		return nil
 	}
 	if _, err := versions.MeetingConstraintsStringRuby(r.Version); err != nil {
-		return err
+		return fmt.Errorf("invalid version constraint %q, err: %s", r.Version, err.Error())
 	}
 	return nil
 }
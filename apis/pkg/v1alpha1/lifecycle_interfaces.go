@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ParseLifeCycle parses the value of the lifecycle annotation,
+// an empty value returns a nil LifeCycle
+func ParseLifeCycle(s string) (*LifeCycle, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	lifecycle := &LifeCycle{}
+	dec := yaml.NewDecoder(strings.NewReader(s))
+	dec.KnownFields(true)
+	if err := dec.Decode(lifecycle); err != nil {
+		return nil, fmt.Errorf("invalid lifecycle %q, err: %s", s, err.Error())
+	}
+	if err := lifecycle.Validate(); err != nil {
+		return nil, err
+	}
+	return lifecycle, nil
+}
+
+// Validate checks the field paths of ignoreChanges
+func (r *LifeCycle) Validate() error {
+	for _, path := range r.IgnoreChanges {
+		if _, err := ParseFieldPath(path); err != nil {
+			return fmt.Errorf("invalid lifecycle ignoreChanges, err: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// String returns the lifecycle in the format of the lifecycle annotation
+func (r *LifeCycle) String() string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (r *LifeCycle) GetPreventDestroy() bool {
+	return r != nil && r.PreventDestroy
+}
+
+func (r *LifeCycle) GetCreateBeforeDestroy() bool {
+	return r != nil && r.CreateBeforeDestroy
+}
+
+func (r *LifeCycle) GetIgnoreChanges() []string {
+	if r == nil {
+		return nil
+	}
+	return r.IgnoreChanges
+}
+
+// GetLifeCycle returns the parsed lifecycle of the block
+func (r *Attributes) GetLifeCycle() (*LifeCycle, error) {
+	return ParseLifeCycle(r.LifeCycle)
+}
+
+// ParseFieldPath splits a field path in its segments. Segments are separated
+// by a dot; a segment between brackets is either a list index or a map key
+// that contains dots, e.g. spec.containers[0].image or metadata.labels[app.kubernetes.io/name]
+func ParseFieldPath(path string) ([]string, error) {
+	segments := []string{}
+	rest := path
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end <= 1 {
+				return nil, fmt.Errorf("invalid field path %q", path)
+			}
+			segments = append(segments, rest[1:end])
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "."):
+			if len(segments) == 0 {
+				return nil, fmt.Errorf("invalid field path %q", path)
+			}
+			rest = rest[1:]
+			if rest == "" || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "[") {
+				return nil, fmt.Errorf("invalid field path %q", path)
+			}
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid field path %q", path)
+	}
+	return segments, nil
+}
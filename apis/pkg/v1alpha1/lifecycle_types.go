@@ -0,0 +1,16 @@
+package v1alpha1
+
+// LifeCycle customizes how the resources of a block are actuated.
+// It is supplied as a yaml/json object in the kform.dev/lifecycle annotation
+// e.g. kform.dev/lifecycle: '{"preventDestroy": true, "ignoreChanges": ["spec.replicas"]}'
+type LifeCycle struct {
+	// PreventDestroy fails the plan/apply when a resource of the block would be destroyed,
+	// either because it is pruned or because the package is destroyed.
+	PreventDestroy bool `json:"preventDestroy,omitempty" yaml:"preventDestroy,omitempty"`
+	// IgnoreChanges are the field paths which are excluded from updates,
+	// e.g. spec.replicas, data.key, spec.containers[0].image or metadata.labels[app.kubernetes.io/name]
+	IgnoreChanges []string `json:"ignoreChanges,omitempty" yaml:"ignoreChanges,omitempty"`
+	// CreateBeforeDestroy creates the new resource of a block before the resource it replaces
+	// is destroyed. By default the replaced resource is destroyed first.
+	CreateBeforeDestroy bool `json:"createBeforeDestroy,omitempty" yaml:"createBeforeDestroy,omitempty"`
+}
@@ -0,0 +1,39 @@
+package v1alpha1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ParseValidation parses the value of the validation annotation,
+// an empty value returns a nil Validation
+func ParseValidation(s string) (*Validation, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	b, err := yaml.YAMLToJSON([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation, err: %s", err.Error())
+	}
+	validation := &Validation{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(validation); err != nil {
+		return nil, fmt.Errorf("invalid validation, err: %s", err.Error())
+	}
+	for i, rule := range validation.Rules {
+		if strings.TrimSpace(rule.Rule) == "" {
+			return nil, fmt.Errorf("invalid validation, rules[%d] requires a rule", i)
+		}
+	}
+	return validation, nil
+}
+
+// GetValidation returns the parsed validation of the block
+func (r *Attributes) GetValidation() (*Validation, error) {
+	return ParseValidation(r.Validation)
+}
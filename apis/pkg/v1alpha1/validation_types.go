@@ -0,0 +1,36 @@
+package v1alpha1
+
+import (
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Validation describes the values an input block accepts.
+// It is supplied as a yaml/json object in the kform.dev/validation annotation, e.g.
+//
+//	kform.dev/validation: |
+//	  schema:
+//	    type: object
+//	    required: [data]
+//	    properties:
+//	      data:
+//	        type: object
+//	        required: [replicas]
+//	        properties:
+//	          replicas: {type: string, pattern: "^[0-9]+$"}
+//	  rules:
+//	  - rule: int(self.data.replicas) < 10
+//	    message: replicas must be lower than 10
+type Validation struct {
+	// Schema is an OpenAPI v3 schema the input must comply with
+	Schema *spec.Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	// Rules are CEL expressions the input must comply with
+	Rules []ValidationRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+type ValidationRule struct {
+	// Rule is a CEL expression which evaluates to true when the input is valid,
+	// the input is referenced as self
+	Rule string `json:"rule" yaml:"rule"`
+	// Message is returned when the rule fails
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
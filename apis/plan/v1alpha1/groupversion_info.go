@@ -0,0 +1,8 @@
+package v1alpha1
+
+const (
+	Group      = "plan.kform.dev"
+	Version    = "v1alpha1"
+	APIVersion = Group + "/" + Version
+	PlanKind   = "Plan"
+)
@@ -0,0 +1,164 @@
+package v1alpha1
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func BuildPlan(pkgName string, invInfo *unstructured.Unstructured, inv *invv1alpha1.Inventory, providers map[string]string) *Plan {
+	var invInfoObj map[string]any
+	if invInfo != nil {
+		invInfoObj = invInfo.Object
+	}
+	return &Plan{
+		APIVersion: APIVersion,
+		Kind:       PlanKind,
+		Spec: PlanSpec{
+			PackageName:   pkgName,
+			InventoryInfo: invInfoObj,
+			Inventory:     inv,
+			Providers:     providers,
+			Packages:      map[string]*PackagePlan{},
+		},
+	}
+}
+
+// ParsePlan unmarshals the plan and validates the plan version
+func ParsePlan(b []byte) (*Plan, error) {
+	plan := &Plan{}
+	if err := yaml.Unmarshal(b, plan); err != nil {
+		return nil, err
+	}
+	if plan.APIVersion != APIVersion || plan.Kind != PlanKind {
+		return nil, fmt.Errorf("unsupported plan, expected %s %s, got: %s %s", APIVersion, PlanKind, plan.APIVersion, plan.Kind)
+	}
+	if plan.Spec.InventoryInfo == nil {
+		return nil, fmt.Errorf("invalid plan, inventoryInfo is required")
+	}
+	return plan, nil
+}
+
+func (r *Plan) Marshal() ([]byte, error) {
+	return yaml.Marshal(r)
+}
+
+// GetInventoryInfo returns the reference to the inventory object of the plan
+func (r *Plan) GetInventoryInfo() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: r.Spec.InventoryInfo}
+}
+
+// IsStale returns true when the inventory differs from the inventory snapshot
+// taken when the plan was created
+func (r *Plan) IsStale(inv *invv1alpha1.Inventory) (bool, error) {
+	if r.Spec.Inventory == nil {
+		r.Spec.Inventory = &invv1alpha1.Inventory{}
+	}
+	if inv == nil {
+		inv = &invv1alpha1.Inventory{}
+	}
+	planInv, err := yaml.Marshal(r.Spec.Inventory)
+	if err != nil {
+		return true, err
+	}
+	currentInv, err := yaml.Marshal(inv)
+	if err != nil {
+		return true, err
+	}
+	return !bytes.Equal(planInv, currentInv), nil
+}
+
+// AddResources adds all resources of the pkgResourcesStore to the plan using
+// the action returned by the actionFn
+func (r *Plan) AddResources(pkgResourcesStore store.Storer[store.Storer[data.BlockData]], actionFn func(pkgName, blockName string, rn *yaml.RNode) Action) error {
+	if pkgResourcesStore == nil {
+		return nil
+	}
+	var errm error
+	pkgResourcesStore.List(func(k store.Key, s store.Storer[data.BlockData]) {
+		pkgName := k.Name
+		s.List(func(k store.Key, bd data.BlockData) {
+			for _, rn := range bd.Get() {
+				if rn == nil {
+					continue
+				}
+				obj := map[string]any{}
+				if err := yaml.Unmarshal([]byte(rn.MustString()), &obj); err != nil {
+					errm = fmt.Errorf("cannot add resource %s to plan, err: %s", k.Name, err.Error())
+					continue
+				}
+				if _, ok := r.Spec.Packages[pkgName]; !ok {
+					r.Spec.Packages[pkgName] = &PackagePlan{Resources: map[string][]Resource{}}
+				}
+				r.Spec.Packages[pkgName].Resources[k.Name] = append(r.Spec.Packages[pkgName].Resources[k.Name], Resource{
+					Action: actionFn(pkgName, k.Name, rn),
+					Object: obj,
+				})
+			}
+		})
+	})
+	return errm
+}
+
+// GetResources returns the resources of the plan that match one of the actions
+func (r *Plan) GetResources(actions ...Action) (store.Storer[store.Storer[data.BlockData]], error) {
+	pkgResourcesStore := memory.NewStore[store.Storer[data.BlockData]](nil)
+	for pkgName, pkgPlan := range r.Spec.Packages {
+		pkgStore := memory.NewStore[data.BlockData](nil)
+		for blockName, resources := range pkgPlan.Resources {
+			bd := data.BlockData{}
+			for _, resource := range resources {
+				if !hasAction(actions, resource.Action) {
+					continue
+				}
+				rn, err := yaml.FromMap(resource.Object)
+				if err != nil {
+					return nil, fmt.Errorf("cannot get resource %s from plan, err: %s", blockName, err.Error())
+				}
+				bd = bd.Add(rn)
+			}
+			if bd.Len() > 0 {
+				pkgStore.Create(store.ToKey(blockName), bd)
+			}
+		}
+		pkgResourcesStore.Create(store.ToKey(pkgName), pkgStore)
+	}
+	return pkgResourcesStore, nil
+}
+
+// GetBlocks returns the blocks <package>/<block> which have resources with one of the actions
+func (r *Plan) GetBlocks(actions ...Action) sets.Set[string] {
+	blocks := sets.New[string]()
+	for pkgName, pkgPlan := range r.Spec.Packages {
+		for blockName, resources := range pkgPlan.Resources {
+			for _, resource := range resources {
+				if hasAction(actions, resource.Action) {
+					blocks.Insert(fmt.Sprintf("%s/%s", pkgName, blockName))
+					break
+				}
+			}
+		}
+	}
+	return blocks
+}
+
+// IsPartial returns true when the plan only acts on a subset of the blocks
+func (r *Plan) IsPartial() bool {
+	return len(r.Spec.Targets) != 0 || len(r.Spec.Excludes) != 0
+}
+
+func hasAction(actions []Action, action Action) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
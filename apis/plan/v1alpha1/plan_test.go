@@ -0,0 +1,102 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestPlan(t *testing.T) {
+	inv := &invv1alpha1.Inventory{
+		Providers: map[string]string{"kubernetes": "apiVersion: kubernetes.provider.kform.dev/v1alpha1\nkind: ProviderConfig\n"},
+		Packages: map[string]*invv1alpha1.PackageInventory{
+			"root": {
+				PackageResources: map[string][]invv1alpha1.Object{
+					"kubernetes_manifest.bla1": {
+						{ObjectRef: invv1alpha1.ObjectReference{Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm1"}},
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		inventory     *invv1alpha1.Inventory
+		expectedStale bool
+	}{
+		"Unchanged": {
+			inventory:     inv,
+			expectedStale: false,
+		},
+		"Changed": {
+			inventory:     &invv1alpha1.Inventory{},
+			expectedStale: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rn := yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\ndata:\n  a: b\n")
+			pkgStore := memory.NewStore[data.BlockData](nil)
+			pkgStore.Create(store.ToKey("kubernetes_manifest.bla1"), data.BlockData{rn})
+			resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+			resources.Create(store.ToKey("root"), pkgStore)
+
+			plan := BuildPlan("root", nil, inv, inv.Providers)
+			plan.Spec.InventoryInfo = map[string]any{"apiVersion": "v1", "kind": "ConfigMap"}
+			if err := plan.AddResources(resources, func(pkgName, blockName string, rn *yaml.RNode) Action {
+				return ActionUpdate
+			}); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			b, err := plan.Marshal()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			newPlan, err := ParsePlan(b)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			stale, err := newPlan.IsStale(tc.inventory)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if stale != tc.expectedStale {
+				t.Errorf("want stale %t, got: %t", tc.expectedStale, stale)
+			}
+
+			updates, err := newPlan.GetResources(ActionUpdate)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			pkgStore, err = updates.Get(store.ToKey("root"))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			bd, err := pkgStore.Get(store.ToKey("kubernetes_manifest.bla1"))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if bd.Len() != 1 || bd.Get()[0].GetName() != "cm1" {
+				t.Errorf("want resource cm1, got: %v", bd)
+			}
+			creates, err := newPlan.GetResources(ActionCreate)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			pkgStore, err = creates.Get(store.ToKey("root"))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if pkgStore.Len() != 0 {
+				t.Errorf("want no resources to create, got: %d", pkgStore.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+)
+
+// Plan is the saved result of a kform plan run. It captures the rendered
+// resources and provider configs together with the inventory as it was
+// seen during planning, such that an apply can actuate exactly what was
+// reviewed without re-rendering the package.
+type Plan struct {
+	APIVersion string   `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Spec       PlanSpec `json:"spec" yaml:"spec"`
+}
+
+type PlanSpec struct {
+	// PackageName is the name of the root package that was planned
+	PackageName string `json:"packageName" yaml:"packageName"`
+	// Path is the directory of the package that was planned, the plan is applied
+	// with the providers installed in the package and recorded in its lock file
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Destroy indicates the plan destroys all resources managed by the inventory
+	Destroy bool `json:"destroy,omitempty" yaml:"destroy,omitempty"`
+	// Targets and Excludes are the blocks that were selected for a partial plan,
+	// the resources of the blocks that are not part of the plan are left untouched
+	Targets  []string `json:"targets,omitempty" yaml:"targets,omitempty"`
+	Excludes []string `json:"excludes,omitempty" yaml:"excludes,omitempty"`
+	// InventoryInfo is the reference to the inventory object in the cluster backend
+	InventoryInfo map[string]any `json:"inventoryInfo" yaml:"inventoryInfo"`
+	// Backend is the config of the backend that stores the inventory w/o its credentials,
+	// the credentials are rendered from the input of the package when the plan is applied
+	Backend *backendv1alpha1.Config `json:"backend,omitempty" yaml:"backend,omitempty"`
+	// Inventory is the snapshot of the inventory used to calculate the plan;
+	// used to detect a stale plan
+	Inventory *invv1alpha1.Inventory `json:"inventory,omitempty" yaml:"inventory,omitempty"`
+	// Providers contains the rendered provider configs per provider
+	Providers map[string]string `json:"providers,omitempty" yaml:"providers,omitempty"`
+	// Blocks contains per package the info of each resource block like the
+	// resource blocks it depends on and its lifecycle
+	Blocks map[string]map[string]*invv1alpha1.BlockInfo `json:"blocks,omitempty" yaml:"blocks,omitempty"`
+	// Packages contains the planned resources per package
+	Packages map[string]*PackagePlan `json:"packages,omitempty" yaml:"packages,omitempty"`
+}
+
+type PackagePlan struct {
+	// Resources contains the planned resources per block <RESOURCE_TYPE>.<RESOURCE_ID>
+	Resources map[string][]Resource `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+type Resource struct {
+	// Action indicates what the apply will do with the resource
+	Action Action `json:"action" yaml:"action"`
+	// Object is the rendered resource
+	Object map[string]any `json:"object" yaml:"object"`
+}
+
+type Action string
+
+const (
+	ActionCreate Action = "Create"
+	ActionUpdate Action = "Update"
+	ActionDelete Action = "Delete"
+	// ActionNoOp indicates the resource is unchanged, it is recorded in the
+	// inventory together with the actuated resources
+	ActionNoOp Action = "NoOp"
+)
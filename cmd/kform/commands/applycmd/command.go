@@ -2,7 +2,9 @@ package applycmd
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/kform-dev/kform/pkg/exec/kform/runner"
 	"github.com/kform-dev/kform/pkg/fsys"
@@ -19,10 +21,11 @@ func NewCommand(ctx context.Context, factory util.Factory, ioStreams genericclio
 // NewRunner returns a command runner.
 func NewRunner(ctx context.Context, factory util.Factory, ioStreams genericclioptions.IOStreams) *Runner {
 	r := &Runner{
-		Factory: factory,
+		Factory:   factory,
+		IOStreams: ioStreams,
 	}
 	cmd := &cobra.Command{
-		Use:  "apply (DIRECTORY | STDIN) [flags]",
+		Use:  "apply (DIRECTORY | PLAN-FILE | STDIN) [flags]",
 		Args: cobra.ExactArgs(1),
 		//Short:   docs.ApplyShort,
 		//Long:    docs.ApplyShort + "\n" + docs.ApplyLong,
@@ -37,38 +40,88 @@ func NewRunner(ctx context.Context, factory util.Factory, ioStreams genericcliop
 	r.Command.Flags().StringVarP(&r.Input, "in", "i", "", "a file or directory of KRM resource(s) that act as input rendering the package")
 	r.Command.Flags().StringVarP(&r.Output, "out", "o", "", "a file or directory where the result is stored, a filename creates a single yaml doc; a dir creates seperated yaml files")
 	r.Command.Flags().StringVar(&r.InventoryID, "inventory-id", "", "iventory-id to identify the applied resources, use valid semantics")
+	r.Command.Flags().BoolVar(&r.ShowSensitive, "show-sensitive", false, "reveals the values of sensitive blocks in the plan and the output")
+	r.Command.Flags().StringArrayVar(&r.Targets, "target", nil, "limits the run to the block <RESOURCE_TYPE>.<RESOURCE_ID> and its dependencies, can be repeated")
+	r.Command.Flags().StringArrayVar(&r.Excludes, "exclude", nil, "excludes the block <RESOURCE_TYPE>.<RESOURCE_ID> and its dependents from the run, can be repeated")
+	r.Command.Flags().IntVar(&r.Parallelism, "parallelism", 10, "limits the number of blocks and block instances that run concurrently, 0 is unlimited")
+	r.Command.Flags().StringToIntVar(&r.ProviderParallelism, "provider-parallelism", nil, "limits the number of concurrent requests per provider, e.g. kubernetes=5")
+	r.Command.Flags().BoolVar(&r.KeepGoing, "keep-going", false, "continues with the blocks that do not depend on a failed block and records what succeeded in the inventory")
+	r.Command.Flags().BoolVar(&r.Wait, "wait", false, "waits till the applied resources are ready before the blocks that depend on them run")
+	r.Command.Flags().DurationVar(&r.Timeout, "timeout", 5*time.Minute, "maximum time to wait for a resource to be ready with --wait, 0 waits indefinitely")
 
 	return r
 }
 
 type Runner struct {
-	Command     *cobra.Command
-	Factory     util.Factory
-	AutoApprove bool
-	DryRun      bool
-	Input       string
-	Output      string
-	InventoryID string
+	Command             *cobra.Command
+	Factory             util.Factory
+	IOStreams           genericclioptions.IOStreams
+	AutoApprove         bool
+	DryRun              bool
+	Input               string
+	Output              string
+	InventoryID         string
+	ShowSensitive       bool
+	Targets             []string
+	Excludes            []string
+	Parallelism         int
+	ProviderParallelism map[string]int
+	KeepGoing           bool
+	Wait                bool
+	Timeout             time.Duration
 }
 
 func (r *Runner) runE(c *cobra.Command, args []string) error {
 	ctx := c.Context()
 	//log := log.FromContext(ctx)
 
+	// a file argument is a saved plan, which is applied w/o re-rendering the package
+	if fsys.FileExists(args[0]) {
+		// the input renders the credentials of the backend, which are not saved in the plan
+		if r.Output != "" || r.InventoryID != "" || r.DryRun || len(r.Targets) != 0 || len(r.Excludes) != 0 {
+			return fmt.Errorf("--out, --inventory-id, --dry-run, --target and --exclude cannot be used with a saved plan")
+		}
+		planFile, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		kfrunner := runner.NewKformRunner(&runner.Config{
+			Factory:             r.Factory,
+			AutoApprove:         r.AutoApprove,
+			Input:               r.Input,
+			PlanFile:            planFile,
+			Parallelism:         r.Parallelism,
+			ProviderParallelism: r.ProviderParallelism,
+			KeepGoing:           r.KeepGoing,
+			Wait:                r.Wait,
+			Timeout:             r.Timeout,
+		})
+		return kfrunner.Run(ctx)
+	}
+
 	path, err := fsys.NormalizeDir(args[0])
 	if err != nil {
 		return err
 	}
 
 	kfrunner := runner.NewKformRunner(&runner.Config{
-		Factory:     r.Factory,
-		PackageName: filepath.Base(path),
-		Input:       r.Input,
-		Output:      r.Output,
-		Path:        path,
-		DryRun:      r.DryRun,
-		AutoApprove: r.AutoApprove,
-		InventoryID: r.InventoryID,
+		Factory:             r.Factory,
+		IOStreams:           r.IOStreams,
+		PackageName:         filepath.Base(path),
+		Input:               r.Input,
+		Output:              r.Output,
+		Path:                path,
+		DryRun:              r.DryRun,
+		AutoApprove:         r.AutoApprove,
+		InventoryID:         r.InventoryID,
+		ShowSensitive:       r.ShowSensitive,
+		Targets:             r.Targets,
+		Excludes:            r.Excludes,
+		Parallelism:         r.Parallelism,
+		ProviderParallelism: r.ProviderParallelism,
+		KeepGoing:           r.KeepGoing,
+		Wait:                r.Wait,
+		Timeout:             r.Timeout,
 	})
 
 	return kfrunner.Run(ctx)
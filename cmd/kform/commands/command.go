@@ -11,8 +11,11 @@ import (
 	"github.com/henderiw/logger/log"
 	"github.com/kform-dev/kform/cmd/kform/commands/applycmd"
 	"github.com/kform-dev/kform/cmd/kform/commands/destroycmd"
+	"github.com/kform-dev/kform/cmd/kform/commands/forceunlockcmd"
 	"github.com/kform-dev/kform/cmd/kform/commands/initcmd"
 	"github.com/kform-dev/kform/cmd/kform/commands/plancmd"
+	"github.com/kform-dev/kform/cmd/kform/commands/providerscmd"
+	"github.com/kform-dev/kform/cmd/kform/commands/statuscmd"
 	"github.com/kform-dev/kform/cmd/kform/globals"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -72,10 +75,12 @@ func GetMain(ctx context.Context) *cobra.Command {
 	}
 
 	subCmds := map[string]*cobra.Command{
-		"init":    initcmd.NewCommand(ctx, ioStreams),
-		"apply":   applycmd.NewCommand(ctx, f, ioStreams),
-		"destroy": destroycmd.NewCommand(ctx, f, ioStreams),
-		"plan":    plancmd.NewCommand(ctx, f, ioStreams),
+		"init":         initcmd.NewCommand(ctx, ioStreams),
+		"apply":        applycmd.NewCommand(ctx, f, ioStreams),
+		"destroy":      destroycmd.NewCommand(ctx, f, ioStreams),
+		"plan":         plancmd.NewCommand(ctx, f, ioStreams),
+		"status":       statuscmd.NewCommand(ctx, f, ioStreams),
+		"force-unlock": forceunlockcmd.NewCommand(ctx, f, ioStreams),
 	}
 
 	for _, subCmd := range subCmds {
@@ -83,6 +88,8 @@ func GetMain(ctx context.Context) *cobra.Command {
 		//updateHelp(names, subCmd)
 		cmd.AddCommand(subCmd)
 	}
+	// the providers commands do not talk to the server
+	cmd.AddCommand(providerscmd.NewCommand(ctx, ioStreams))
 	cmd.AddCommand(GetVersionCommand(ctx))
 	return cmd
 }
@@ -19,7 +19,8 @@ func NewCommand(ctx context.Context, factory util.Factory, ioStreams genericclio
 // NewRunner returns a command runner.
 func NewRunner(ctx context.Context, factory util.Factory, ioStreams genericclioptions.IOStreams) *Runner {
 	r := &Runner{
-		Factory: factory,
+		Factory:   factory,
+		IOStreams: ioStreams,
 	}
 	cmd := &cobra.Command{
 		Use:  "destroy (DIRECTORY | STDIN) [flags]",
@@ -35,18 +36,31 @@ func NewRunner(ctx context.Context, factory util.Factory, ioStreams genericcliop
 	r.Command.Flags().BoolVar(&r.AutoApprove, "auto-approve", false, "skip interactive approval of plan before destroying")
 	r.Command.Flags().BoolVar(&r.DryRun, "dry-run", false, "executes a speculative execution plan, without destroying the resources")
 	r.Command.Flags().StringVar(&r.InventoryID, "inventory-id", "", "iventory-id to identify the applied resources, use valid semantics")
+	r.Command.Flags().BoolVar(&r.ShowSensitive, "show-sensitive", false, "reveals the values of sensitive blocks in the plan and the output")
+	r.Command.Flags().StringArrayVar(&r.Targets, "target", nil, "limits the destroy to the block <RESOURCE_TYPE>.<RESOURCE_ID> and the blocks that depend on it, can be repeated")
+	r.Command.Flags().StringArrayVar(&r.Excludes, "exclude", nil, "excludes the block <RESOURCE_TYPE>.<RESOURCE_ID> and the blocks it depends on from the destroy, can be repeated")
+	r.Command.Flags().IntVar(&r.Parallelism, "parallelism", 10, "limits the number of blocks and block instances that run concurrently, 0 is unlimited")
+	r.Command.Flags().StringToIntVar(&r.ProviderParallelism, "provider-parallelism", nil, "limits the number of concurrent requests per provider, e.g. kubernetes=5")
+	r.Command.Flags().BoolVar(&r.KeepGoing, "keep-going", false, "continues destroying the blocks that are not depended on by a failed block")
 
 	return r
 }
 
 type Runner struct {
-	Command     *cobra.Command
-	Factory     util.Factory
-	AutoApprove bool
-	DryRun      bool
-	Input       string
-	Output      string
-	InventoryID string
+	Command             *cobra.Command
+	Factory             util.Factory
+	IOStreams           genericclioptions.IOStreams
+	AutoApprove         bool
+	DryRun              bool
+	Input               string
+	Output              string
+	InventoryID         string
+	ShowSensitive       bool
+	Targets             []string
+	Excludes            []string
+	Parallelism         int
+	ProviderParallelism map[string]int
+	KeepGoing           bool
 }
 
 func (r *Runner) runE(c *cobra.Command, args []string) error {
@@ -59,15 +73,22 @@ func (r *Runner) runE(c *cobra.Command, args []string) error {
 	}
 
 	kfrunner := runner.NewKformRunner(&runner.Config{
-		Factory:     r.Factory,
-		PackageName: filepath.Base(path),
-		Input:       r.Input,
-		Output:      r.Output,
-		Path:        path,
-		Destroy:     true,
-		AutoApprove: r.AutoApprove,
-		DryRun:      r.DryRun,
-		InventoryID: r.InventoryID,
+		Factory:             r.Factory,
+		IOStreams:           r.IOStreams,
+		PackageName:         filepath.Base(path),
+		Input:               r.Input,
+		Output:              r.Output,
+		Path:                path,
+		Destroy:             true,
+		AutoApprove:         r.AutoApprove,
+		DryRun:              r.DryRun,
+		InventoryID:         r.InventoryID,
+		ShowSensitive:       r.ShowSensitive,
+		Targets:             r.Targets,
+		Excludes:            r.Excludes,
+		Parallelism:         r.Parallelism,
+		ProviderParallelism: r.ProviderParallelism,
+		KeepGoing:           r.KeepGoing,
 	})
 
 	return kfrunner.Run(ctx)
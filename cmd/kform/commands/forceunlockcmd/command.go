@@ -0,0 +1,73 @@
+package forceunlockcmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/kform-dev/kform/pkg/exec/kform/runner"
+	"github.com/kform-dev/kform/pkg/fsys"
+	"github.com/kform-dev/kform/pkg/inventory/config"
+	"github.com/kform-dev/kform/pkg/inventory/manager"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+func NewCommand(ctx context.Context, factory util.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return NewRunner(ctx, factory, ioStreams).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, factory util.Factory, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		Factory:   factory,
+		IOStreams: ioStreams,
+	}
+	cmd := &cobra.Command{
+		Use:   "force-unlock INVENTORY-ID [DIRECTORY] [flags]",
+		Short: "releases the lock of an inventory held by a run that got stuck",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  r.runE,
+	}
+
+	r.Command = cmd
+
+	r.Command.Flags().StringVarP(&r.Input, "in", "i", "", "a file or directory of KRM resource(s) that act as input rendering the backend block of the package")
+
+	return r
+}
+
+type Runner struct {
+	Command   *cobra.Command
+	Factory   util.Factory
+	IOStreams genericclioptions.IOStreams
+	Input     string
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+
+	dir := "."
+	if len(args) > 1 {
+		dir = args[1]
+	}
+	path, err := fsys.NormalizeDir(dir)
+	if err != nil {
+		return err
+	}
+	// the input renders the expressions of the backend block
+	backendConfig, err := runner.GetBackendConfig(ctx, &runner.Config{
+		PackageName: filepath.Base(path),
+		Path:        path,
+		Input:       r.Input,
+	})
+	if err != nil {
+		return err
+	}
+	if err := manager.ForceUnlock(ctx, config.GetFakeInventoryInfo(args[0]), r.Factory, backendConfig); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.IOStreams.Out, "inventory %s unlocked\n", args[0])
+	return nil
+}
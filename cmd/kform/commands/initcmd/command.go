@@ -2,8 +2,15 @@ package initcmd
 
 import (
 	"context"
+	"path/filepath"
 
+	"github.com/kform-dev/kform/cmd/kform/globals"
+	"github.com/kform-dev/kform/pkg/fsys"
 	"github.com/kform-dev/kform/pkg/inventory/config"
+	"github.com/kform-dev/kform/pkg/recorder"
+	"github.com/kform-dev/kform/pkg/recorder/diag"
+	"github.com/kform-dev/kform/pkg/syntax/parser"
+	"github.com/kform-dev/kform/pkg/syntax/types"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	//docs "github.com/kform-dev/kform/internal/docs/generated/applydocs"
@@ -29,24 +36,56 @@ func NewRunner(ctx context.Context, ioStreams genericclioptions.IOStreams) *Runn
 
 	r.Command = cmd
 	r.Command.Flags().StringVarP(&r.InvConfig.InventoryID, "inventory-id", "i", "", "iventory-id to identify the applied resources, use valid semantics")
+	r.Command.Flags().BoolVar(&r.Upgrade, "upgrade", false, "upgrade the providers to the newest versions meeting the version constraints, ignoring the lock file")
 	return r
 }
 
 type Runner struct {
 	Command   *cobra.Command
 	InvConfig *config.Config
+	Upgrade   bool
 }
 
 func (r *Runner) runE(c *cobra.Command, args []string) error {
 	ctx := c.Context()
+	path := args[0]
 
-	if r.InvConfig.InventoryID == "" {
-		err := r.InvConfig.Complete(ctx, args[0])
-		if err != nil {
+	// the inventory is only created once, such that init can be rerun to install providers
+	if r.InvConfig.InventoryID == "" && !fsys.FileExists(config.InventoryPath(path)) {
+		if err := r.InvConfig.Complete(ctx, path); err != nil {
 			return err
 		}
-		return r.InvConfig.Run(ctx)
+		if err := r.InvConfig.Run(ctx); err != nil {
+			return err
+		}
+	}
+	return installProviders(ctx, path, r.Upgrade)
+}
+
+// installProviders parses the package, installs the required providers in the plugin dir
+// and records the installed releases in the lock file. The providers are installed from the
+// filesystem mirror when configured in the CLI config file.
+func installProviders(ctx context.Context, path string, upgrade bool) error {
+	cliConfig, err := globals.ReadCLIConfig()
+	if err != nil {
+		return err
 	}
-	return nil
+	initRecorder := recorder.New[diag.Diagnostic]()
+	ctx = context.WithValue(ctx, types.CtxKeyRecorder, initRecorder)
 
+	p, err := parser.NewKformParser(ctx, &parser.Config{
+		PackageName:       filepath.Base(path),
+		Path:              path,
+		PluginDir:         parser.GetPluginDir(path),
+		PluginCacheDir:    globals.GetPluginCacheDir(cliConfig),
+		ProviderMirrorDir: cliConfig.GetFilesystemMirrorPath(),
+		LockFile:          parser.GetLockFile(path),
+		InstallProviders:  true,
+		UpgradeProviders:  upgrade,
+	})
+	if err != nil {
+		return err
+	}
+	p.Parse(ctx)
+	return initRecorder.Get().Error()
 }
@@ -2,6 +2,7 @@ package plancmd
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 
 	"github.com/kform-dev/kform/pkg/exec/kform/runner"
@@ -19,7 +20,8 @@ func NewCommand(ctx context.Context, factory util.Factory, ioStreams genericclio
 // NewRunner returns a command runner.
 func NewRunner(ctx context.Context, factory util.Factory, ioStreams genericclioptions.IOStreams) *Runner {
 	r := &Runner{
-		Factory: factory,
+		Factory:   factory,
+		IOStreams: ioStreams,
 	}
 	cmd := &cobra.Command{
 		Use:  "plan (DIRECTORY | STDIN) [flags]",
@@ -36,38 +38,65 @@ func NewRunner(ctx context.Context, factory util.Factory, ioStreams genericcliop
 	r.Command.Flags().StringVarP(&r.Input, "in", "i", "", "a file or directory of KRM resource(s) that act as input rendering the package")
 	r.Command.Flags().StringVarP(&r.Output, "out", "o", "", "a file or directory where the result is stored, a filename creates a single yaml doc; a dir creates seperated yaml files")
 	r.Command.Flags().StringVar(&r.InventoryID, "inventory-id", "", "iventory-id to identify the applied resources, use valid semantics")
+	r.Command.Flags().StringVar(&r.OutputFormat, "output-format", runner.OutputFormatText, "format in which the plan is printed: text or json, in json format the result is only written with --out")
+	r.Command.Flags().StringVar(&r.PlanOut, "plan-out", "", "a file where the plan is saved, which can be applied using kform apply <PLAN-FILE>")
+	r.Command.Flags().BoolVar(&r.ShowSensitive, "show-sensitive", false, "reveals the values of sensitive blocks in the plan and the output")
+	r.Command.Flags().StringArrayVar(&r.Targets, "target", nil, "limits the run to the block <RESOURCE_TYPE>.<RESOURCE_ID> and its dependencies, can be repeated")
+	r.Command.Flags().StringArrayVar(&r.Excludes, "exclude", nil, "excludes the block <RESOURCE_TYPE>.<RESOURCE_ID> and its dependents from the run, can be repeated")
+	r.Command.Flags().IntVar(&r.Parallelism, "parallelism", 10, "limits the number of blocks and block instances that run concurrently, 0 is unlimited")
+	r.Command.Flags().StringToIntVar(&r.ProviderParallelism, "provider-parallelism", nil, "limits the number of concurrent requests per provider, e.g. kubernetes=5")
 
 	return r
 }
 
 type Runner struct {
-	Command     *cobra.Command
-	Factory     util.Factory
-	AutoApprove bool
-	Destroy     bool
-	Input       string
-	Output      string
-	InventoryID string
+	Command             *cobra.Command
+	Factory             util.Factory
+	IOStreams           genericclioptions.IOStreams
+	AutoApprove         bool
+	Destroy             bool
+	Input               string
+	Output              string
+	InventoryID         string
+	PlanOut             string
+	OutputFormat        string
+	ShowSensitive       bool
+	Targets             []string
+	Excludes            []string
+	Parallelism         int
+	ProviderParallelism map[string]int
 }
 
 func (r *Runner) runE(c *cobra.Command, args []string) error {
 	ctx := c.Context()
 	//log := log.FromContext(ctx)
 
+	if r.OutputFormat != runner.OutputFormatText && r.OutputFormat != runner.OutputFormatJSON {
+		return fmt.Errorf("invalid output-format %q, supported formats: %s, %s", r.OutputFormat, runner.OutputFormatText, runner.OutputFormatJSON)
+	}
+
 	path, err := fsys.NormalizeDir(args[0])
 	if err != nil {
 		return err
 	}
 
 	kfrunner := runner.NewKformRunner(&runner.Config{
-		Factory:     r.Factory,
-		PackageName: filepath.Base(path),
-		Input:       r.Input,
-		Output:      r.Output,
-		Path:        path,
-		DryRun:      true,
-		Destroy:     r.Destroy,
-		InventoryID: r.InventoryID,
+		Factory:             r.Factory,
+		IOStreams:           r.IOStreams,
+		PackageName:         filepath.Base(path),
+		Input:               r.Input,
+		Output:              r.Output,
+		Path:                path,
+		DryRun:              true,
+		Destroy:             r.Destroy,
+		InventoryID:         r.InventoryID,
+		ShowSensitive:       r.ShowSensitive,
+		Targets:             r.Targets,
+		Excludes:            r.Excludes,
+		Parallelism:         r.Parallelism,
+		ProviderParallelism: r.ProviderParallelism,
+		PlanOut:             r.PlanOut,
+		OutputFormat:        r.OutputFormat,
 	})
 
 	return kfrunner.Run(ctx)
@@ -0,0 +1,71 @@
+package cachecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kform-dev/kform/cmd/kform/globals"
+	"github.com/kform-dev/kform/pkg/fsys"
+	"github.com/kform-dev/kform/pkg/syntax/parser"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func NewCommand(ctx context.Context, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "manages the plugin cache shared by all packages, located by KFORM_PLUGIN_CACHE_DIR or the CLI config file",
+	}
+	cmd.AddCommand(NewRunner(ctx, ioStreams).Command)
+	return cmd
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		IOStreams: ioStreams,
+	}
+	cmd := &cobra.Command{
+		Use:   "prune [DIRECTORY...] [flags]",
+		Short: "deletes the providers from the plugin cache that are not locked by the packages in the directories",
+		Long: "deletes the providers from the plugin cache that are not locked by the lock files of the packages " +
+			"in the directories, without directories all providers are deleted from the plugin cache",
+		RunE: r.runE,
+	}
+
+	r.Command = cmd
+	r.Command.Flags().StringVar(&r.CacheDir, "cache-dir", "", "directory of the plugin cache, defaults to KFORM_PLUGIN_CACHE_DIR or the CLI config file")
+	return r
+}
+
+type Runner struct {
+	Command   *cobra.Command
+	IOStreams genericclioptions.IOStreams
+	CacheDir  string
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	if r.CacheDir == "" {
+		cliConfig, err := globals.ReadCLIConfig()
+		if err != nil {
+			return err
+		}
+		r.CacheDir = globals.GetPluginCacheDir(cliConfig)
+	}
+	if r.CacheDir == "" {
+		return fmt.Errorf("no plugin cache, set KFORM_PLUGIN_CACHE_DIR, pluginCacheDir in the CLI config file or --cache-dir")
+	}
+	lockFiles := make([]string, 0, len(args))
+	for _, dir := range args {
+		path, err := fsys.NormalizeDir(dir)
+		if err != nil {
+			return err
+		}
+		lockFiles = append(lockFiles, parser.GetLockFile(path))
+	}
+	pruned, err := parser.PrunePluginCache(r.CacheDir, lockFiles)
+	for _, id := range pruned {
+		fmt.Fprintf(r.IOStreams.Out, "pruned %s\n", id)
+	}
+	return err
+}
@@ -0,0 +1,20 @@
+package providerscmd
+
+import (
+	"context"
+
+	"github.com/kform-dev/kform/cmd/kform/commands/providerscmd/cachecmd"
+	"github.com/kform-dev/kform/cmd/kform/commands/providerscmd/mirrorcmd"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func NewCommand(ctx context.Context, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "manages the providers of kform packages",
+	}
+	cmd.AddCommand(cachecmd.NewCommand(ctx, ioStreams))
+	cmd.AddCommand(mirrorcmd.NewCommand(ctx, ioStreams))
+	return cmd
+}
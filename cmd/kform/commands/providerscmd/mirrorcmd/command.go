@@ -0,0 +1,57 @@
+package mirrorcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kform-dev/kform/pkg/fsys"
+	"github.com/kform-dev/kform/pkg/syntax/parser"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func NewCommand(ctx context.Context, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return NewRunner(ctx, ioStreams).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		IOStreams: ioStreams,
+	}
+	cmd := &cobra.Command{
+		Use:   "mirror MIRROR-DIRECTORY [DIRECTORY] [flags]",
+		Args:  cobra.RangeArgs(1, 2),
+		Short: "copies the providers locked by the package into a filesystem mirror",
+		Long: "copies the provider releases locked by the lock file of the package in the directory, for all locked " +
+			"platforms, from the registries into OCI image layouts in the filesystem mirror, such that kform init " +
+			"can install the providers without access to the registries. The directory defaults to the current directory.",
+		RunE: r.runE,
+	}
+
+	r.Command = cmd
+	return r
+}
+
+type Runner struct {
+	Command   *cobra.Command
+	IOStreams genericclioptions.IOStreams
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+	mirrorDir := args[0]
+	dir := "."
+	if len(args) > 1 {
+		dir = args[1]
+	}
+	path, err := fsys.NormalizeDir(dir)
+	if err != nil {
+		return err
+	}
+	mirrored, err := parser.MirrorProviders(ctx, parser.GetLockFile(path), mirrorDir)
+	for _, ref := range mirrored {
+		fmt.Fprintf(r.IOStreams.Out, "mirrored %s\n", ref)
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package statuscmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/exec/kform/runner"
+	"github.com/kform-dev/kform/pkg/fsys"
+	"github.com/kform-dev/kform/pkg/inventory/config"
+	"github.com/kform-dev/kform/pkg/inventory/manager"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+func NewCommand(ctx context.Context, factory util.Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return NewRunner(ctx, factory, ioStreams).Command
+}
+
+// NewRunner returns a command runner.
+func NewRunner(ctx context.Context, factory util.Factory, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		Factory:   factory,
+		IOStreams: ioStreams,
+	}
+	cmd := &cobra.Command{
+		Use:   "status [DIRECTORY] [flags]",
+		Short: "shows the status of the last actuation of the objects in the inventory",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  r.runE,
+	}
+
+	r.Command = cmd
+
+	r.Command.Flags().StringVarP(&r.Input, "in", "i", "", "a file or directory of KRM resource(s) that act as input rendering the backend block of the package")
+	r.Command.Flags().StringVar(&r.InventoryID, "inventory-id", "", "iventory-id to identify the applied resources, use valid semantics")
+
+	return r
+}
+
+type Runner struct {
+	Command     *cobra.Command
+	Factory     util.Factory
+	IOStreams   genericclioptions.IOStreams
+	Input       string
+	InventoryID string
+}
+
+func (r *Runner) runE(c *cobra.Command, args []string) error {
+	ctx := c.Context()
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	path, err := fsys.NormalizeDir(dir)
+	if err != nil {
+		return err
+	}
+	var localInventory *unstructured.Unstructured
+	if r.InventoryID != "" {
+		localInventory = config.GetFakeInventoryInfo(r.InventoryID)
+	} else {
+		localInventory, err = config.GetInventoryInfo(path)
+		if err != nil {
+			return err
+		}
+	}
+	// the input renders the expressions of the backend block
+	backendConfig, err := runner.GetBackendConfig(ctx, &runner.Config{
+		PackageName: filepath.Base(path),
+		Path:        path,
+		Input:       r.Input,
+	})
+	if err != nil {
+		return err
+	}
+
+	// reading the status does not lock the inventory, such that it can be used during a run
+	invManager, err := manager.New(ctx, localInventory, r.Factory, backendConfig, invv1alpha1.ActuationStrategyApply, false)
+	if err != nil {
+		return err
+	}
+	inventory, err := invManager.GetInventory(ctx)
+	if err != nil {
+		return err
+	}
+	return printStatus(r.IOStreams, inventory)
+}
+
+type objectEntry struct {
+	pkgName   string
+	blockName string
+	invv1alpha1.Object
+}
+
+// printStatus prints a table of the objects in the inventory with the status of their last
+// actuation and reconciliation, sorted by package, block, kind, namespace and name
+func printStatus(ioStreams genericclioptions.IOStreams, inventory *invv1alpha1.Inventory) error {
+	entries := []objectEntry{}
+	for pkgName, pkgInv := range inventory.Packages {
+		if pkgInv == nil {
+			continue
+		}
+		for blockName, objs := range pkgInv.PackageResources {
+			for _, obj := range objs {
+				entries = append(entries, objectEntry{pkgName: pkgName, blockName: blockName, Object: obj})
+			}
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(ioStreams.Out, "no objects found in the inventory")
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.pkgName != b.pkgName {
+			return a.pkgName < b.pkgName
+		}
+		if a.blockName != b.blockName {
+			return a.blockName < b.blockName
+		}
+		if a.ObjectRef.Kind != b.ObjectRef.Kind {
+			return a.ObjectRef.Kind < b.ObjectRef.Kind
+		}
+		if a.ObjectRef.Namespace != b.ObjectRef.Namespace {
+			return a.ObjectRef.Namespace < b.ObjectRef.Namespace
+		}
+		return a.ObjectRef.Name < b.ObjectRef.Name
+	})
+
+	w := tabwriter.NewWriter(ioStreams.Out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tBLOCK\tKIND\tNAMESPACE\tNAME\tSTRATEGY\tACTUATION\tRECONCILE\tACTUATED")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.pkgName,
+			e.blockName,
+			e.ObjectRef.Kind,
+			e.ObjectRef.Namespace,
+			e.ObjectRef.Name,
+			e.Strategy.String(),
+			e.Actuation.String(),
+			e.Reconcile.String(),
+			formatTime(e.ActuationTime),
+		)
+	}
+	return w.Flush()
+}
+
+func formatTime(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
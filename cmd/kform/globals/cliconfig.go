@@ -0,0 +1,63 @@
+package globals
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	cliv1alpha1 "github.com/kform-dev/kform/apis/cli/v1alpha1"
+	"github.com/kform-dev/kform/pkg/syntax/parser"
+)
+
+// cliConfigFileEnv is the env variable that locates the CLI config file
+const cliConfigFileEnv = "KFORM_CLI_CONFIG_FILE"
+
+// GetCLIConfigFile returns the CLI config file, by default ~/.kform/config.yaml
+func GetCLIConfigFile() string {
+	if file := os.Getenv(cliConfigFileEnv); file != "" {
+		return file
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kform", "config.yaml")
+}
+
+// ReadCLIConfig reads the CLI config file, an empty config is returned when the file does not exist.
+// A relative filesystem mirror path is relative to the directory of the config file.
+func ReadCLIConfig() (*cliv1alpha1.Config, error) {
+	file := GetCLIConfigFile()
+	if file == "" {
+		return cliv1alpha1.BuildConfig(), nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cliv1alpha1.BuildConfig(), nil
+		}
+		return nil, err
+	}
+	cfg, err := cliv1alpha1.ParseConfig(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file %s, err: %s", file, err.Error())
+	}
+	if mirror := cfg.Spec.ProviderInstallation.GetFilesystemMirror(); mirror != nil && !filepath.IsAbs(mirror.Path) {
+		mirror.Path = filepath.Join(filepath.Dir(file), mirror.Path)
+	}
+	return cfg, nil
+}
+
+// GetPluginCacheDir returns the directory of the shared plugin cache, located by the
+// KFORM_PLUGIN_CACHE_DIR env variable or otherwise the CLI config
+func GetPluginCacheDir(cfg *cliv1alpha1.Config) string {
+	if dir := parser.GetPluginCacheDir(); dir != "" {
+		return dir
+	}
+	if cfg == nil {
+		return ""
+	}
+	return cfg.Spec.PluginCacheDir
+}
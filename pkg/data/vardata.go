@@ -78,9 +78,9 @@ func UpdateVarStore(ctx context.Context, varStore store.Storer[VarData], blockNa
 		return fmt.Errorf("index cannot be bigger or equal to total index: %d, totol: %d", indexInt, totalInt)
 	}
 	var errm error
-	log.Debug("update varStore entry", "key", store.ToKey(blockName), "totalInt", totalInt, "indexInt", indexInt, "data", data)
+	// the data is not logged as the varStore is not aware if the data is sensitive
+	log.Debug("update varStore entry", "key", store.ToKey(blockName), "totalInt", totalInt, "indexInt", indexInt)
 	varStore.UpdateWithKeyFn(store.ToKey(blockName), func(varData VarData) VarData {
-		log.Debug("update varStore", "key", store.ToKey(blockName), "varData", varData, "totalInt", totalInt, "indexInt", indexInt, "data", data)
 		if varData == nil {
 			varData = VarData{}
 		}
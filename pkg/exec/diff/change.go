@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionNoOp   Action = "no-op"
+)
+
+// Change represents the difference of a single object between the existing
+// resources and the newly rendered resources
+type Change struct {
+	Action      Action
+	PackageName string
+	// BlockName is the name of the block <RESOURCE_TYPE>.<RESOURCE_ID>
+	BlockName string
+	// Index is the index of the object within the block
+	Index int
+	GVK   schema.GroupVersionKind
+	NSN   types.NamespacedName
+	// FieldPaths contains the field paths that are changed by an update
+	FieldPaths []string
+	Before     *unstructured.Unstructured
+	After      *unstructured.Unstructured
+}
+
+func (r *Change) fileName() string {
+	group := ""
+	if r.GVK.Group != "" {
+		group = r.GVK.Group + "."
+	}
+	return group + strings.Join([]string{r.GVK.Version, r.GVK.Kind, r.NSN.Namespace, r.NSN.Name}, ".")
+}
+
+// UnifiedDiff returns the unified diff between the before and after object
+func (r *Change) UnifiedDiff() (string, error) {
+	before, err := toYAML(r.Before)
+	if err != nil {
+		return "", err
+	}
+	after, err := toYAML(r.After)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: "FROM/" + r.fileName(),
+		ToFile:   "TO/" + r.fileName(),
+		Context:  3,
+	})
+}
+
+func toYAML(u *unstructured.Unstructured) (string, error) {
+	if u == nil {
+		return "", nil
+	}
+	b, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
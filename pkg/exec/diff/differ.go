@@ -2,61 +2,66 @@ package diff
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"sort"
 
 	"github.com/henderiw/logger/log"
 	"github.com/henderiw/store"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
-	"github.com/kform-dev/kform/pkg/fsys"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 type Differ struct {
-	From *DiffContext
-	To   *DiffContext
+	from       store.Storer[store.Storer[data.BlockData]]
+	to         store.Storer[store.Storer[data.BlockData]]
+	blockInfos map[string]map[string]*invv1alpha1.BlockInfo
+	// showSensitive reveals the values of sensitive blocks in the changes
+	showSensitive bool
+	changes       []*Change
 }
 
-func NewDiffer(from, to store.Storer[store.Storer[data.BlockData]]) (*Differ, error) {
-	var err error
-	differ := &Differ{}
-	differ.From, err = NewDiffContext("FROM", from)
-	if err != nil {
-		return nil, err
-	}
-	differ.To, err = NewDiffContext("TO", to)
-	if err != nil {
-		return nil, err
+// NewDiffer returns a differ of the existing (from) and new (to) resources, the blockInfos
+// supply the lifecycle which is used to ignore changes and the sensitivity of the blocks.
+// The values of sensitive blocks are masked unless showSensitive is set.
+func NewDiffer(from, to store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*invv1alpha1.BlockInfo, showSensitive bool) *Differ {
+	return &Differ{
+		from:          from,
+		to:            to,
+		blockInfos:    blockInfos,
+		showSensitive: showSensitive,
 	}
-	return differ, nil
 }
 
+// GetResourceToPrune returns the existing resources that are not part of the
+// new resources; only valid after Run
 func (r *Differ) GetResourceToPrune() store.Storer[store.Storer[data.BlockData]] {
-	return r.From.Store
+	return r.from
 }
 
-func (r *Differ) FromPath() string {
-	return r.From.Path()
+// GetChanges returns the change set sorted by package, block and index; only valid after Run
+func (r *Differ) GetChanges() []*Change {
+	return r.changes
 }
 
-func (r *Differ) ToPath() string {
-	return r.To.Path()
-}
-
-// Run first prints the files in the respective directories and applies masking if needed for sensitive data
-// On top we prune the from with the netries that are in common
-// Afterwards the
+// Run compares the new resources with the existing resources and builds the change set.
+// On top we prune the from with the entries that are in common, such that the remainder
+// represent the resources to be deleted.
 func (r *Differ) Run(ctx context.Context) error {
 	log := log.FromContext(ctx)
-	// we walk over the to store
+	r.changes = []*Change{}
 	var errm error
-	if r.To.Store != nil {
-		r.To.Store.List(func(pkgKey store.Key, pkgStore store.Storer[data.BlockData]) {
+	if r.to != nil {
+		r.to.List(func(pkgKey store.Key, pkgStore store.Storer[data.BlockData]) {
 			pkgStore.List(func(blockKey store.Key, bd data.BlockData) {
-				for _, toRn := range bd.Get() {
-					fileName, err := fsys.GetFileName(toRn)
-					if err != nil {
-						log.Error("cannot get name from RNode", "error", err.Error())
-						errm = errors.Join(errm, err)
+				for idx, toRn := range bd.Get() {
+					if toRn == nil {
 						continue
 					}
 					to, err := convertRNodeToUnstructured(toRn)
@@ -65,51 +70,58 @@ func (r *Differ) Run(ctx context.Context) error {
 						log.Error("cannot convert rn to unstructured", "error", err)
 						continue
 					}
-					from := r.From.GetStoreItem(ctx, pkgKey.Name, blockKey.Name, toRn)
+					change := newChange(pkgKey.Name, blockKey.Name, idx, to)
 
-					if gvk := to.GetObjectKind().GroupVersionKind(); gvk.Version == "v1" && gvk.Kind == "Secret" {
-						m, err := NewMasker(from, to)
+					fromRn := r.getStoreItem(ctx, pkgKey.Name, blockKey.Name, toRn)
+					if fromRn == nil {
+						change.Action = ActionCreate
+						change.After = to
+					} else {
+						from, err := convertRNodeToUnstructured(fromRn)
 						if err != nil {
 							errm = errors.Join(errm, err)
 							log.Error("cannot convert rn to unstructured", "error", err)
 							continue
 						}
-						from, to = m.From(), m.To()
-					}
-
-					// print the file to the respective directories; if nil no worries
-					if err := r.To.Print(fileName, to); err != nil {
-						errm = errors.Join(errm, err)
-						log.Error("cannot print to", "fileName", fileName, "error", err)
-						continue
-					}
-					if err := r.From.Print(fileName, from); err != nil {
-						errm = errors.Join(errm, err)
-						log.Error("cannot print to", "fileName", fileName, "error", err)
-						continue
-					}
+						// the fields for which changes are ignored retain their existing value
+						ignoreChanges := r.blockInfos[pkgKey.Name][blockKey.Name].GetLifeCycle().GetIgnoreChanges()
+						if err := IgnoreChanges(from.Object, to.Object, ignoreChanges); err != nil {
+							errm = errors.Join(errm, err)
+							log.Error("cannot ignore changes", "error", err)
+							continue
+						}
+						before := &unstructured.Unstructured{Object: project(from.Object, to.Object).(map[string]any)}
+						change.FieldPaths = fieldPaths(before.Object, to.Object)
+						change.Action = ActionUpdate
+						if len(change.FieldPaths) == 0 {
+							change.Action = ActionNoOp
+						}
+						change.Before = before
+						change.After = to
 
-					if from != nil {
 						// delete the item from the origin, such that we dont prune the item from the cluster
-						if err := r.From.DeleteStoreItem(ctx, pkgKey.Name, blockKey.Name, toRn); err != nil {
+						if err := r.deleteStoreItem(ctx, pkgKey.Name, blockKey.Name, toRn); err != nil {
 							errm = errors.Join(errm, err)
 							log.Error("cannot delete block item from store", "error", err)
 							continue
 						}
 					}
+					if err := change.mask(r.isSensitive(pkgKey.Name, blockKey.Name)); err != nil {
+						errm = errors.Join(errm, err)
+						log.Error("cannot mask sensitive data", "error", err)
+						continue
+					}
+					r.changes = append(r.changes, change)
 				}
 			})
 		})
 	}
-	if r.From.Store != nil {
-		// update the remainder of the from
-		r.From.Store.List(func(pkgKey store.Key, pkgStore store.Storer[data.BlockData]) {
+	if r.from != nil {
+		// the remainder of the from are the resources to be deleted
+		r.from.List(func(pkgKey store.Key, pkgStore store.Storer[data.BlockData]) {
 			pkgStore.List(func(blockKey store.Key, bd data.BlockData) {
-				for _, fromRn := range bd.Get() {
-					fileName, err := fsys.GetFileName(fromRn)
-					if err != nil {
-						log.Error("cannot get name from RNode", "error", err.Error())
-						errm = errors.Join(errm, err)
+				for idx, fromRn := range bd.Get() {
+					if fromRn == nil {
 						continue
 					}
 					from, err := convertRNodeToUnstructured(fromRn)
@@ -118,37 +130,119 @@ func (r *Differ) Run(ctx context.Context) error {
 						log.Error("cannot convert rn to unstructured", "error", err)
 						continue
 					}
-					var to runtime.Object
-
-					if gvk := from.GetObjectKind().GroupVersionKind(); gvk.Version == "v1" && gvk.Kind == "Secret" {
-						m, err := NewMasker(from, to)
-						if err != nil {
-							errm = errors.Join(errm, err)
-							log.Error("cannot convert rn to unstructured", "error", err)
-							continue
-						}
-						from, to = m.From(), m.To()
-					}
-
-					// print the file to the respective directories; if nil no worries
-					if err := r.To.Print(fileName, to); err != nil {
+					from.SetManagedFields(nil)
+					change := newChange(pkgKey.Name, blockKey.Name, idx, from)
+					change.Action = ActionDelete
+					change.Before = from
+					if err := change.mask(r.isSensitive(pkgKey.Name, blockKey.Name)); err != nil {
 						errm = errors.Join(errm, err)
-						log.Error("cannot print to", "fileName", fileName, "error", err)
-						continue
-					}
-					if err := r.From.Print(fileName, from); err != nil {
-						errm = errors.Join(errm, err)
-						log.Error("cannot print to", "fileName", fileName, "error", err)
+						log.Error("cannot mask sensitive data", "error", err)
 						continue
 					}
+					r.changes = append(r.changes, change)
 				}
 			})
 		})
 	}
+	sort.SliceStable(r.changes, func(i, j int) bool {
+		if r.changes[i].PackageName != r.changes[j].PackageName {
+			return r.changes[i].PackageName < r.changes[j].PackageName
+		}
+		if r.changes[i].BlockName != r.changes[j].BlockName {
+			return r.changes[i].BlockName < r.changes[j].BlockName
+		}
+		return r.changes[i].Index < r.changes[j].Index
+	})
 	return errm
 }
 
-func (r *Differ) TearDown() {
-	r.From.DeleteDir() // we can ignore the error
-	r.To.DeleteDir()   // we can ignore the error
+// Print writes the unified diff of all changes, objects without changes are skipped
+func (r *Differ) Print(w io.Writer) error {
+	for _, change := range r.changes {
+		if change.Action == ActionNoOp {
+			continue
+		}
+		diff, err := change.UnifiedDiff()
+		if err != nil {
+			return fmt.Errorf("cannot diff %s[%d], err: %s", change.BlockName, change.Index, err.Error())
+		}
+		fmt.Fprint(w, diff)
+	}
+	return nil
+}
+
+// isSensitive returns true when the values of the block must be masked
+func (r *Differ) isSensitive(pkgName, blockName string) bool {
+	return !r.showSensitive && r.blockInfos[pkgName][blockName].IsSensitive()
+}
+
+func (r *Differ) getStoreItem(ctx context.Context, pkgName, blockName string, rn *yaml.RNode) *yaml.RNode {
+	if r.from == nil {
+		return nil
+	}
+	pkgStore, err := r.from.Get(store.ToKey(pkgName))
+	if err != nil {
+		// not a worry as this means the package did not exist
+		return nil
+	}
+	return data.GetBlockStoreEntry(ctx, pkgStore, blockName, rn)
+}
+
+func (r *Differ) deleteStoreItem(ctx context.Context, pkgName, blockName string, rn *yaml.RNode) error {
+	// get the pkgStore in which we store the resources actuated per package
+	pkgStore, err := r.from.Get(store.ToKey(pkgName))
+	if err != nil {
+		// not a worry as this means the package did not exist
+		return nil
+	}
+	return data.DeleteBlockStoreEntry(ctx, pkgStore, blockName, rn)
+}
+
+func newChange(pkgName, blockName string, idx int, u *unstructured.Unstructured) *Change {
+	return &Change{
+		PackageName: pkgName,
+		BlockName:   blockName,
+		Index:       idx,
+		GVK:         u.GroupVersionKind(),
+		NSN:         types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()},
+	}
+}
+
+// mask hides the data of secrets and all values of sensitive blocks
+// while keeping the changes visible
+func (r *Change) mask(sensitive bool) error {
+	if sensitive {
+		r.Before, r.After = maskObjects(r.Before, r.After)
+		return nil
+	}
+	if r.GVK.Group != "" || r.GVK.Version != "v1" || r.GVK.Kind != "Secret" {
+		return nil
+	}
+	var from, to runtime.Object
+	if r.Before != nil {
+		from = r.Before
+	}
+	if r.After != nil {
+		to = r.After
+	}
+	m, err := NewMasker(from, to)
+	if err != nil {
+		return err
+	}
+	r.Before, _ = m.From().(*unstructured.Unstructured)
+	r.After, _ = m.To().(*unstructured.Unstructured)
+	return nil
+}
+
+func convertRNodeToUnstructured(rn *yaml.RNode) (*unstructured.Unstructured, error) {
+	// Convert RNode directly to Unstructured
+	b, err := rn.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var v map[string]any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: v}, nil
 }
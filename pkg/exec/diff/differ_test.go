@@ -0,0 +1,218 @@
+package diff
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func newResources(t *testing.T, docs ...string) store.Storer[store.Storer[data.BlockData]] {
+	pkgStore := memory.NewStore[data.BlockData](nil)
+	bd := data.BlockData{}
+	for _, doc := range docs {
+		rn, err := yaml.Parse(doc)
+		if err != nil {
+			t.Fatalf("cannot parse doc: %s", err)
+		}
+		bd = bd.Add(rn)
+	}
+	pkgStore.Create(store.ToKey("kubernetes_manifest.cm"), bd)
+	resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+	resources.Create(store.ToKey("root"), pkgStore)
+	return resources
+}
+
+func TestDiffer(t *testing.T) {
+	cm1 := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\ndata:\n  a: b\n"
+	cm1Live := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\n  uid: 1234\n  resourceVersion: \"1\"\ndata:\n  a: b\n"
+	cm1Changed := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\ndata:\n  a: c\n  d: e\n"
+	cm2 := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm2\n  namespace: default\ndata:\n  a: b\n"
+	secret := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: s1\n  namespace: default\ndata:\n  password: c2VjcmV0\n"
+
+	tests := map[string]struct {
+		from              []string
+		to                []string
+		expectedActions   []Action
+		expectedPaths     [][]string
+		expectedDiff      []string
+		unexpectedDiff    []string
+		expectedPruneObjs int
+		lifecycle         *kformv1alpha1.LifeCycle
+		sensitive         bool
+		showSensitive     bool
+	}{
+		"Create": {
+			from:            nil,
+			to:              []string{cm1},
+			expectedActions: []Action{ActionCreate},
+			expectedPaths:   [][]string{nil},
+			expectedDiff:    []string{"+++ TO/v1.ConfigMap.default.cm1", "+  a: b"},
+		},
+		"NoOp": {
+			from:            []string{cm1Live},
+			to:              []string{cm1},
+			expectedActions: []Action{ActionNoOp},
+			expectedPaths:   [][]string{{}},
+			unexpectedDiff:  []string{"cm1"},
+		},
+		"Update": {
+			from:            []string{cm1Live},
+			to:              []string{cm1Changed},
+			expectedActions: []Action{ActionUpdate},
+			expectedPaths:   [][]string{{"data.a", "data.d"}},
+			expectedDiff:    []string{"-  a: b", "+  a: c", "+  d: e"},
+		},
+		"Delete": {
+			from:              []string{cm1Live, cm2},
+			to:                []string{cm1},
+			expectedActions:   []Action{ActionNoOp, ActionDelete},
+			expectedPaths:     [][]string{{}, nil},
+			expectedDiff:      []string{"--- FROM/v1.ConfigMap.default.cm2", "-  a: b"},
+			expectedPruneObjs: 1,
+		},
+		"IgnoreChanges": {
+			from:            []string{cm1Live},
+			to:              []string{cm1Changed},
+			expectedActions: []Action{ActionUpdate},
+			expectedPaths:   [][]string{{"data.d"}},
+			expectedDiff:    []string{"+  d: e"},
+			unexpectedDiff:  []string{"a: c"},
+			lifecycle:       &kformv1alpha1.LifeCycle{IgnoreChanges: []string{"data.a"}},
+		},
+		"Secret": {
+			from:            nil,
+			to:              []string{secret},
+			expectedActions: []Action{ActionCreate},
+			expectedPaths:   [][]string{nil},
+			expectedDiff:    []string{"password: '***'"},
+			unexpectedDiff:  []string{"c2VjcmV0"},
+		},
+		"Sensitive": {
+			from:            []string{cm1Live},
+			to:              []string{cm1Changed},
+			expectedActions: []Action{ActionUpdate},
+			expectedPaths:   [][]string{{"data.a", "data.d"}},
+			expectedDiff:    []string{"name: cm1", "-  a: '*** (before)'", "+  a: '*** (after)'", "+  d: '***'"},
+			unexpectedDiff:  []string{"a: b", "a: c", "d: e"},
+			sensitive:       true,
+		},
+		"ShowSensitive": {
+			from:            []string{cm1Live},
+			to:              []string{cm1Changed},
+			expectedActions: []Action{ActionUpdate},
+			expectedPaths:   [][]string{{"data.a", "data.d"}},
+			expectedDiff:    []string{"-  a: b", "+  a: c", "+  d: e"},
+			sensitive:       true,
+			showSensitive:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			var from store.Storer[store.Storer[data.BlockData]]
+			if tc.from != nil {
+				from = newResources(t, tc.from...)
+			}
+			blockInfos := map[string]map[string]*invv1alpha1.BlockInfo{
+				"root": {"kubernetes_manifest.cm": {LifeCycle: tc.lifecycle, Sensitive: tc.sensitive}},
+			}
+			differ := NewDiffer(from, newResources(t, tc.to...), blockInfos, tc.showSensitive)
+			if err := differ.Run(ctx); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			changes := differ.GetChanges()
+			if len(changes) != len(tc.expectedActions) {
+				t.Fatalf("want %d changes, got: %d", len(tc.expectedActions), len(changes))
+			}
+			for i, change := range changes {
+				if change.Action != tc.expectedActions[i] {
+					t.Errorf("change %d: want action %s, got: %s", i, tc.expectedActions[i], change.Action)
+				}
+				if d := cmp.Diff(tc.expectedPaths[i], change.FieldPaths); d != "" {
+					t.Errorf("change %d: -want paths, +got:\n%s", i, d)
+				}
+			}
+
+			var sb strings.Builder
+			if err := differ.Print(&sb); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for _, s := range tc.expectedDiff {
+				if !strings.Contains(sb.String(), s) {
+					t.Errorf("want diff to contain %q, got:\n%s", s, sb.String())
+				}
+			}
+			for _, s := range tc.unexpectedDiff {
+				if strings.Contains(sb.String(), s) {
+					t.Errorf("want diff not to contain %q, got:\n%s", s, sb.String())
+				}
+			}
+
+			pruneObjs := 0
+			if prune := differ.GetResourceToPrune(); prune != nil {
+				prune.List(func(k store.Key, s store.Storer[data.BlockData]) {
+					s.List(func(k store.Key, bd data.BlockData) {
+						pruneObjs += bd.Len()
+					})
+				})
+			}
+			if pruneObjs != tc.expectedPruneObjs {
+				t.Errorf("want %d objects to prune, got: %d", tc.expectedPruneObjs, pruneObjs)
+			}
+		})
+	}
+}
+
+func TestJSONPlan(t *testing.T) {
+	ctx := context.Background()
+	from := newResources(t,
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\ndata:\n  a: b\n",
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm2\n  namespace: default\ndata:\n  a: b\n",
+	)
+	to := newResources(t,
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\ndata:\n  a: c\n",
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm3\n  namespace: default\ndata:\n  a: b\n",
+	)
+	differ := NewDiffer(from, to, nil, false)
+	if err := differ.Run(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var sb strings.Builder
+	differ.PrintSummary(&sb)
+	for _, s := range []string{
+		"Plan: 1 to add, 1 to change, 1 to destroy.",
+		"package root: 1 to add, 1 to change, 1 to destroy",
+		"  kubernetes_manifest.cm: 1 to add, 1 to change, 1 to destroy",
+	} {
+		if !strings.Contains(sb.String(), s) {
+			t.Errorf("want summary to contain %q, got:\n%s", s, sb.String())
+		}
+	}
+
+	plan := differ.GetJSONPlan()
+	if plan.FormatVersion != FormatVersion {
+		t.Errorf("want formatVersion %s, got: %s", FormatVersion, plan.FormatVersion)
+	}
+	got := map[string]Action{}
+	for _, rc := range plan.ResourceChanges {
+		got[rc.Address+" "+rc.NSN.Name] = rc.Action
+	}
+	expected := map[string]Action{
+		"kubernetes_manifest.cm[0] cm1": ActionUpdate,
+		"kubernetes_manifest.cm[1] cm3": ActionCreate,
+		"kubernetes_manifest.cm[0] cm2": ActionDelete,
+	}
+	if d := cmp.Diff(expected, got); d != "" {
+		t.Errorf("-want resourceChanges, +got:\n%s", d)
+	}
+}
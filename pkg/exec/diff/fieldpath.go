@@ -0,0 +1,170 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+)
+
+// project returns the from object limited to the fields that are present in
+// the to object. Fields that are only present in the existing object are
+// populated by the server or managed by someone else, so they are not
+// considered part of the change.
+func project(from, to any) any {
+	switch to := to.(type) {
+	case map[string]any:
+		from, ok := from.(map[string]any)
+		if !ok {
+			return from
+		}
+		projected := make(map[string]any, len(to))
+		for k, v := range to {
+			if fv, ok := from[k]; ok {
+				projected[k] = project(fv, v)
+			}
+		}
+		return projected
+	case []any:
+		from, ok := from.([]any)
+		if !ok || len(from) != len(to) {
+			return from
+		}
+		projected := make([]any, len(to))
+		for i := range to {
+			projected[i] = project(from[i], to[i])
+		}
+		return projected
+	default:
+		return from
+	}
+}
+
+// fieldPaths returns the sorted field paths which differ between from and to
+func fieldPaths(from, to any) []string {
+	paths := []string{}
+	collectFieldPaths("", from, to, &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func collectFieldPaths(path string, from, to any, paths *[]string) {
+	switch to := to.(type) {
+	case map[string]any:
+		from, ok := from.(map[string]any)
+		if !ok {
+			*paths = append(*paths, path)
+			return
+		}
+		for k, v := range to {
+			collectFieldPaths(childPath(path, k), from[k], v, paths)
+		}
+		for k, v := range from {
+			if _, ok := to[k]; !ok {
+				collectFieldPaths(childPath(path, k), v, nil, paths)
+			}
+		}
+	case []any:
+		from, ok := from.([]any)
+		if !ok || len(from) != len(to) {
+			*paths = append(*paths, path)
+			return
+		}
+		for i := range to {
+			collectFieldPaths(fmt.Sprintf("%s[%d]", path, i), from[i], to[i], paths)
+		}
+	default:
+		if !reflect.DeepEqual(from, to) {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+func childPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// IgnoreChanges sets the fields of the paths in the to object to the value they
+// have in the from object, such that an update does not change them. Fields that
+// are not present in the from object are removed from the to object.
+func IgnoreChanges(from, to map[string]any, paths []string) error {
+	for _, path := range paths {
+		segments, err := kformv1alpha1.ParseFieldPath(path)
+		if err != nil {
+			return err
+		}
+		v, found := getField(from, segments)
+		setField(to, segments, v, found)
+	}
+	return nil
+}
+
+func getField(obj any, segments []string) (any, bool) {
+	if len(segments) == 0 {
+		return obj, true
+	}
+	switch obj := obj.(type) {
+	case map[string]any:
+		v, ok := obj[segments[0]]
+		if !ok {
+			return nil, false
+		}
+		return getField(v, segments[1:])
+	case []any:
+		idx, err := strconv.Atoi(segments[0])
+		if err != nil || idx < 0 || idx >= len(obj) {
+			return nil, false
+		}
+		return getField(obj[idx], segments[1:])
+	default:
+		return nil, false
+	}
+}
+
+// setField sets the value at the path of the segments, when found is false
+// the field is removed
+func setField(obj any, segments []string, v any, found bool) {
+	last := len(segments) == 1
+	switch obj := obj.(type) {
+	case map[string]any:
+		if last {
+			if found {
+				obj[segments[0]] = v
+			} else {
+				delete(obj, segments[0])
+			}
+			return
+		}
+		child, ok := obj[segments[0]]
+		if !ok {
+			if !found {
+				return
+			}
+			child = map[string]any{}
+			obj[segments[0]] = child
+		}
+		setField(child, segments[1:], v, found)
+	case []any:
+		idx, err := strconv.Atoi(segments[0])
+		if err != nil || idx < 0 || idx >= len(obj) {
+			return
+		}
+		if last {
+			// list entries cannot be removed w/o shifting the other entries
+			if found {
+				obj[idx] = v
+			}
+			return
+		}
+		setField(obj[idx], segments[1:], v, found)
+	}
+}
@@ -2,9 +2,11 @@ package diff
 
 import (
 	"fmt"
+	"reflect"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // Constants for masking sensitive values
@@ -14,6 +16,10 @@ const (
 	sensitiveMaskAfter   = "*** (after)"
 )
 
+// identityFields are the field paths which are not masked in sensitive objects
+// as they identify the object
+var identityFields = sets.New[string]("apiVersion", "kind", "metadata.name", "metadata.namespace")
+
 // Masker masks sensitive values in an object while preserving diff-able
 // changes.
 //
@@ -132,3 +138,56 @@ func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
 	u.SetUnstructuredContent(c)
 	return u, nil
 }
+
+// maskObjects masks all values of the objects, except the fields that identify the
+// object. Like the Masker, values that are present in both objects and differ get a
+// before/after suffix so they can be diff-ed.
+func maskObjects(from, to *unstructured.Unstructured) (*unstructured.Unstructured, *unstructured.Unstructured) {
+	var fromObj, toObj map[string]any
+	if from != nil {
+		fromObj = from.Object
+	}
+	if to != nil {
+		toObj = to.Object
+	}
+	if from != nil {
+		from = &unstructured.Unstructured{Object: maskValue("", fromObj, toObj, sensitiveMaskBefore).(map[string]any)}
+	}
+	if to != nil {
+		to = &unstructured.Unstructured{Object: maskValue("", toObj, fromObj, sensitiveMaskAfter).(map[string]any)}
+	}
+	return from, to
+}
+
+// maskValue returns a masked copy of the value, other is the value at the same path
+// in the object it is compared with
+func maskValue(path string, v, other any, changedMask string) any {
+	if identityFields.Has(path) {
+		return v
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		otherMap, _ := other.(map[string]any)
+		masked := make(map[string]any, len(v))
+		for k, cv := range v {
+			masked[k] = maskValue(childPath(path, k), cv, otherMap[k], changedMask)
+		}
+		return masked
+	case []any:
+		otherList, _ := other.([]any)
+		masked := make([]any, len(v))
+		for i, cv := range v {
+			var ov any
+			if i < len(otherList) {
+				ov = otherList[i]
+			}
+			masked[i] = maskValue(fmt.Sprintf("%s[%d]", path, i), cv, ov, changedMask)
+		}
+		return masked
+	default:
+		if other != nil && !reflect.DeepEqual(v, other) {
+			return changedMask
+		}
+		return sensitiveMaskDefault
+	}
+}
@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// FormatVersion is the version of the json plan schema; it changes when
+// fields are removed or their meaning changes
+const FormatVersion = "v1"
+
+// JSONPlan is the machine readable representation of the change set
+type JSONPlan struct {
+	FormatVersion   string            `json:"formatVersion"`
+	Summary         *Summary          `json:"summary"`
+	ResourceChanges []*ResourceChange `json:"resourceChanges"`
+}
+
+type ResourceChange struct {
+	// Address is the address of the object <RESOURCE_TYPE>.<RESOURCE_ID>[INDEX]
+	Address    string         `json:"address"`
+	Package    string         `json:"package"`
+	Action     Action         `json:"action"`
+	GVK        GVK            `json:"gvk"`
+	NSN        NSN            `json:"nsn"`
+	FieldPaths []string       `json:"fieldPaths,omitempty"`
+	Before     map[string]any `json:"before"`
+	After      map[string]any `json:"after"`
+}
+
+type GVK struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+type NSN struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Address returns the address of the object <RESOURCE_TYPE>.<RESOURCE_ID>[INDEX]
+func (r *Change) Address() string {
+	return fmt.Sprintf("%s[%d]", r.BlockName, r.Index)
+}
+
+// GetJSONPlan returns the json representation of the change set; only valid after Run
+func (r *Differ) GetJSONPlan() *JSONPlan {
+	plan := &JSONPlan{
+		FormatVersion:   FormatVersion,
+		Summary:         r.GetSummary(),
+		ResourceChanges: make([]*ResourceChange, 0, len(r.changes)),
+	}
+	for _, change := range r.changes {
+		resourceChange := &ResourceChange{
+			Address:    change.Address(),
+			Package:    change.PackageName,
+			Action:     change.Action,
+			GVK:        GVK{Group: change.GVK.Group, Version: change.GVK.Version, Kind: change.GVK.Kind},
+			NSN:        NSN{Namespace: change.NSN.Namespace, Name: change.NSN.Name},
+			FieldPaths: change.FieldPaths,
+		}
+		if change.Before != nil {
+			resourceChange.Before = change.Before.Object
+		}
+		if change.After != nil {
+			resourceChange.After = change.After.Object
+		}
+		plan.ResourceChanges = append(plan.ResourceChanges, resourceChange)
+	}
+	return plan
+}
+
+// PrintJSON writes the json representation of the change set
+func (r *Differ) PrintJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.GetJSONPlan())
+}
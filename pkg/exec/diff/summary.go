@@ -0,0 +1,80 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+)
+
+// Count holds the number of objects per action
+type Count struct {
+	Add     int `json:"add"`
+	Change  int `json:"change"`
+	Destroy int `json:"destroy"`
+}
+
+func (r *Count) add(action Action) {
+	switch action {
+	case ActionCreate:
+		r.Add++
+	case ActionUpdate:
+		r.Change++
+	case ActionDelete:
+		r.Destroy++
+	}
+}
+
+func (r Count) String() string {
+	return fmt.Sprintf("%d to add, %d to change, %d to destroy", r.Add, r.Change, r.Destroy)
+}
+
+// Summary holds the counts of the change set in total and grouped by package and block
+type Summary struct {
+	Count
+	Packages []*PackageSummary `json:"packages"`
+}
+
+type PackageSummary struct {
+	Name string `json:"name"`
+	Count
+	Blocks []*BlockSummary `json:"blocks"`
+}
+
+type BlockSummary struct {
+	Name string `json:"name"`
+	Count
+}
+
+// GetSummary returns the summary of the change set; only valid after Run
+func (r *Differ) GetSummary() *Summary {
+	summary := &Summary{Packages: []*PackageSummary{}}
+	// the changes are sorted by package and block so we only need to look at the last entry
+	var pkgSummary *PackageSummary
+	var blockSummary *BlockSummary
+	for _, change := range r.changes {
+		if pkgSummary == nil || pkgSummary.Name != change.PackageName {
+			pkgSummary = &PackageSummary{Name: change.PackageName, Blocks: []*BlockSummary{}}
+			summary.Packages = append(summary.Packages, pkgSummary)
+			blockSummary = nil
+		}
+		if blockSummary == nil || blockSummary.Name != change.BlockName {
+			blockSummary = &BlockSummary{Name: change.BlockName}
+			pkgSummary.Blocks = append(pkgSummary.Blocks, blockSummary)
+		}
+		summary.add(change.Action)
+		pkgSummary.add(change.Action)
+		blockSummary.add(change.Action)
+	}
+	return summary
+}
+
+// PrintSummary writes the total counts followed by the counts per package and block
+func (r *Differ) PrintSummary(w io.Writer) {
+	summary := r.GetSummary()
+	fmt.Fprintf(w, "\nPlan: %s.\n", summary.Count)
+	for _, pkgSummary := range summary.Packages {
+		fmt.Fprintf(w, "\npackage %s: %s\n", pkgSummary.Name, pkgSummary.Count)
+		for _, blockSummary := range pkgSummary.Blocks {
+			fmt.Fprintf(w, "  %s: %s\n", blockSummary.Name, blockSummary.Count)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package executor
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,11 +28,14 @@ import (
 )
 
 type DAGExecutor interface {
+	// Run executes the DAG and returns true when all vertices succeeded
 	Run(ctx context.Context) bool
+	// GetResults returns the result per vertex of the last run
+	GetResults() VertexResults
 }
 
 type ExecHandler[T any] interface {
-	BlockRun(ctx context.Context, vertexName string, vertexContext T) bool
+	BlockRun(ctx context.Context, vertexName string, vertexContext T) error
 	PostRun(ctx context.Context, start, finish time.Time, success bool)
 }
 
@@ -42,6 +46,19 @@ type Config[T any] struct {
 	Name    string
 	From    string
 	Handler ExecHandler[T]
+	// Reverse executes the DAG in reverse order, a vertex waits for all its
+	// downstream vertices to finish; used to delete resources leaves first
+	Reverse bool
+	// Limiter bounds the vertices that run concurrently, the limiter can be
+	// shared with the handler to bound the instances of a vertex as well
+	Limiter *Limiter
+	// Limited returns true when the vertex runs in a slot of the Limiter, when nil all
+	// vertices get a slot. Vertices that only schedule work that acquires its own slots,
+	// like a package that runs its own DAG, should not hold a slot.
+	Limited func(vertexContext T) bool
+	// KeepGoing continues the execution after a vertex failed, only the
+	// vertices that depend on the failed vertex are skipped
+	KeepGoing bool
 }
 
 func NewDAGExecutor[T any](ctx context.Context, d dag.DAG[T], cfg *Config[T]) (DAGExecutor, error) {
@@ -60,11 +77,10 @@ func NewDAGExecutor[T any](ctx context.Context, d dag.DAG[T], cfg *Config[T]) (D
 	}
 
 	r := &dagExecutor[T]{
-		cfg:       *cfg,
-		d:         d,
-		m:         sync.RWMutex{},
-		execMap:   map[string]*execContext[T]{},
-		fnDoneMap: map[string]chan bool{},
+		cfg:     *cfg,
+		d:       d,
+		m:       sync.RWMutex{},
+		execMap: map[string]*execContext[T]{},
 	}
 
 	// initialize the initial data in the executor
@@ -80,9 +96,10 @@ type dagExecutor[T any] struct {
 	cancelFn context.CancelFunc
 
 	// used during the Walk func
-	m         sync.RWMutex
-	execMap   map[string]*execContext[T]
-	fnDoneMap map[string]chan bool
+	m       sync.RWMutex
+	execMap map[string]*execContext[T]
+	// used by the vertices to signal the main walk they finished
+	doneFnCh chan string
 }
 
 // init initializes the executor with channels and cancel context
@@ -93,8 +110,11 @@ func (r *dagExecutor[T]) init(ctx context.Context) {
 		log.Error("init failed, no DAG supplied")
 		return
 	}
-	//r.execMap = map[string]*execContext{}
-	for vertexName, v := range r.d.GetVertices() {
+	vertices := r.d.GetVertices()
+	// buffered such that a vertex never waits for the main walk to
+	// receive its result, e.g. while it holds a slot of the limiter
+	r.doneFnCh = make(chan string, len(vertices))
+	for vertexName, v := range vertices {
 		log.Debug("init", "vertexName", vertexName)
 		r.execMap[vertexName] = &execContext[T]{
 			execName:      r.cfg.Name,
@@ -102,6 +122,7 @@ func (r *dagExecutor[T]) init(ctx context.Context) {
 			vertexContext: v,
 			doneChs:       make(map[string]chan bool), //snd
 			depChs:        make(map[string]chan bool), //rcv
+			doneFnCh:      r.doneFnCh,
 			deps:          make([]string, 0),
 			// handler instance of ExecHandler to execute the
 			// specific implementation of the vertex
@@ -117,23 +138,31 @@ func (r *dagExecutor[T]) init(ctx context.Context) {
 	// used to wait for the upstream vertex to signal the fn/job is done
 	for vertexName, execCtx := range r.execMap {
 		// only run these channels when we want to add dependency validation
-		for _, depVertexName := range r.d.GetUpVertexes(vertexName) {
+		deps := r.getDependencies(vertexName)
+		for _, depVertexName := range deps {
 			depCh := make(chan bool)
 			r.execMap[depVertexName].AddDoneCh(vertexName, depCh) // send when done
 			execCtx.AddDepCh(depVertexName, depCh)                // rcvr when done
 		}
-		execCtx.deps = r.d.GetUpVertexes(vertexName)
-		doneFnCh := make(chan bool)
-		execCtx.doneFnCh = doneFnCh
-		r.fnDoneMap[vertexName] = doneFnCh
+		execCtx.deps = deps
 	}
 }
 
+// getDependencies returns the vertices the vertex needs to wait for
+// in reverse mode these are the downstream vertices
+func (r *dagExecutor[T]) getDependencies(vertexName string) []string {
+	if r.cfg.Reverse {
+		return r.d.GetDownVertexes(vertexName)
+	}
+	return r.d.GetUpVertexes(vertexName)
+}
+
 // Run
 func (r *dagExecutor[T]) Run(ctx context.Context) bool {
 	from := r.cfg.From
 	start := time.Now()
 	ctx, cancelFn := context.WithCancel(ctx)
+	defer cancelFn()
 	r.cancelFn = cancelFn
 	success := r.execute(ctx, from, true)
 	finish := time.Now()
@@ -159,8 +188,20 @@ func (r *dagExecutor[T]) execute(ctx context.Context, from string, init bool) bo
 			if !r.dependenciesFinished(execCtx.depChs) {
 				log.Debug("not finished", "vertexname", from)
 			}
-			if !execCtx.waitDependencies(ctx) {
-				// TODO gather info why the failure occured
+			if failed := execCtx.waitDependencies(ctx); len(failed) != 0 {
+				execCtx.skip(ctx, fmt.Sprintf("upstream vertices did not succeed: %s", strings.Join(failed, ", ")))
+				return
+			}
+			// a limited vertex only runs when it gets a slot of the limiter
+			if r.cfg.Limited == nil || r.cfg.Limited(execCtx.vertexContext) {
+				if err := r.cfg.Limiter.Acquire(ctx); err != nil {
+					execCtx.skip(ctx, "execution cancelled")
+					return
+				}
+				defer r.cfg.Limiter.Release()
+			}
+			if ctx.Err() != nil {
+				execCtx.skip(ctx, "execution cancelled")
 				return
 			}
 			// execute the vertex function
@@ -194,28 +235,34 @@ func (r *dagExecutor[T]) dependenciesFinished(dep map[string]chan bool) bool {
 	return true
 }
 
+// waitFunctionCompletion waits till all vertices signalled they finished, the first
+// failure cancels the execution such that the vertices that did not start are skipped,
+// unless the execution keeps going
 func (r *dagExecutor[T]) waitFunctionCompletion(ctx context.Context) bool {
 	log := log.FromContext(ctx)
 	log.Debug("main walk wait waiting for function completion...")
-DepSatisfied:
-	for vertexName, doneFnCh := range r.fnDoneMap {
-		for {
-			select {
-			case d, ok := <-doneFnCh:
-				log.Debug("main walk wait rcvd fn done", "from", vertexName, "success", d, "ok", ok)
-				if !d {
-					r.cancelFn()
-					return false
-				}
-				continue DepSatisfied
-			case <-ctx.Done():
-				// called when the controller gets cancelled
-				return false
-			case <-time.After(time.Second * 5):
-				log.Debug("main walk wait timeout, waiting", "for", vertexName)
+	success := true
+	for finished := 0; finished < len(r.execMap); finished++ {
+		vertexName := <-r.doneFnCh
+		result := r.getExecContext(vertexName).getResult()
+		log.Debug("main walk wait rcvd fn done", "from", vertexName, "success", result.Success, "skipped", result.Skipped)
+		if !result.Success && success {
+			success = false
+			if !r.cfg.KeepGoing {
+				r.cancelFn()
 			}
 		}
 	}
-	log.Debug("main walk wait function completion waiting finished - bye !")
-	return true
+	log.Debug("main walk wait function completion waiting finished - bye !", "success", success)
+	return success
+}
+
+func (r *dagExecutor[T]) GetResults() VertexResults {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	results := make(VertexResults, len(r.execMap))
+	for vertexName, execCtx := range r.execMap {
+		results[vertexName] = execCtx.getResult()
+	}
+	return results
 }
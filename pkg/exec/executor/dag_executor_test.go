@@ -0,0 +1,285 @@
+/*
+Copyright 2023 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/kform-dev/kform/pkg/dag"
+)
+
+type testHandler struct {
+	m      sync.Mutex
+	order  []string
+	failed map[string]bool
+}
+
+func (r *testHandler) BlockRun(ctx context.Context, vertexName string, vertexContext string) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.order = append(r.order, vertexName)
+	if r.failed[vertexName] {
+		return fmt.Errorf("%s failed", vertexName)
+	}
+	return nil
+}
+
+func (r *testHandler) PostRun(ctx context.Context, start, finish time.Time, success bool) {}
+
+func TestDAGExecutor(t *testing.T) {
+	// root -> ns -> deployment -> service
+	cases := map[string]struct {
+		reverse         bool
+		failed          map[string]bool
+		expectedOrder   []string
+		expectedSuccess bool
+		expectedFailed  []string
+		expectedSkipped []string
+	}{
+		"Forward": {
+			reverse:         false,
+			expectedOrder:   []string{dag.Root, "ns", "deployment", "service"},
+			expectedSuccess: true,
+		},
+		"Reverse": {
+			reverse:         true,
+			expectedOrder:   []string{"service", "deployment", "ns", dag.Root},
+			expectedSuccess: true,
+		},
+		"ReverseFailure": {
+			reverse:         true,
+			failed:          map[string]bool{"deployment": true},
+			expectedOrder:   []string{"service", "deployment"},
+			expectedSuccess: false,
+			expectedFailed:  []string{"deployment"},
+			expectedSkipped: []string{"ns", dag.Root},
+		},
+		"ForwardFailure": {
+			reverse:         false,
+			failed:          map[string]bool{"ns": true},
+			expectedOrder:   []string{dag.Root, "ns"},
+			expectedSuccess: false,
+			expectedFailed:  []string{"ns"},
+			expectedSkipped: []string{"deployment", "service"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			d := dag.New[string]()
+			for _, v := range []string{dag.Root, "ns", "deployment", "service"} {
+				d.AddVertex(ctx, v, v)
+			}
+			d.Connect(ctx, dag.Root, "ns")
+			d.Connect(ctx, "ns", "deployment")
+			d.Connect(ctx, "deployment", "service")
+
+			h := &testHandler{failed: tc.failed}
+			e, err := NewDAGExecutor[string](ctx, d, &Config[string]{
+				Name:    name,
+				From:    dag.Root,
+				Handler: h,
+				Reverse: tc.reverse,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			success := e.Run(ctx)
+			if success != tc.expectedSuccess {
+				t.Errorf("want success %t, got: %t", tc.expectedSuccess, success)
+			}
+			// give vertices that should not run the chance to run
+			time.Sleep(10 * time.Millisecond)
+			h.m.Lock()
+			defer h.m.Unlock()
+			if d := cmp.Diff(tc.expectedOrder, h.order); d != "" {
+				t.Errorf("-want order, +got:\n%s", d)
+			}
+			results := e.GetResults()
+			if d := cmp.Diff(tc.expectedFailed, results.Failed(), cmpopts.EquateEmpty()); d != "" {
+				t.Errorf("-want failed, +got:\n%s", d)
+			}
+			if d := cmp.Diff(tc.expectedSkipped, results.Skipped(), cmpopts.EquateEmpty()); d != "" {
+				t.Errorf("-want skipped, +got:\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDAGExecutorKeepGoing(t *testing.T) {
+	// root -> ns -> deployment -> service
+	//      -> cm
+	ctx := context.Background()
+	d := dag.New[string]()
+	for _, v := range []string{dag.Root, "ns", "deployment", "service", "cm"} {
+		d.AddVertex(ctx, v, v)
+	}
+	d.Connect(ctx, dag.Root, "ns")
+	d.Connect(ctx, "ns", "deployment")
+	d.Connect(ctx, "deployment", "service")
+	d.Connect(ctx, dag.Root, "cm")
+
+	h := &testHandler{failed: map[string]bool{"deployment": true}}
+	e, err := NewDAGExecutor[string](ctx, d, &Config[string]{
+		Name:      "KeepGoing",
+		From:      dag.Root,
+		Handler:   h,
+		KeepGoing: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if e.Run(ctx) {
+		t.Errorf("want failure, got success")
+	}
+	results := e.GetResults()
+	if d := cmp.Diff([]string{"deployment"}, results.Failed()); d != "" {
+		t.Errorf("-want failed, +got:\n%s", d)
+	}
+	if d := cmp.Diff([]string{"service"}, results.Skipped()); d != "" {
+		t.Errorf("-want skipped, +got:\n%s", d)
+	}
+	for _, vertexName := range []string{dag.Root, "ns", "cm"} {
+		if !results[vertexName].Success {
+			t.Errorf("want %s to succeed, got: %v", vertexName, results[vertexName])
+		}
+	}
+}
+
+type parallelismHandler struct {
+	m       sync.Mutex
+	running int
+	max     int
+}
+
+func (r *parallelismHandler) BlockRun(ctx context.Context, vertexName string, vertexContext string) error {
+	r.m.Lock()
+	r.running++
+	if r.running > r.max {
+		r.max = r.running
+	}
+	r.m.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	r.m.Lock()
+	r.running--
+	r.m.Unlock()
+	return nil
+}
+
+func (r *parallelismHandler) PostRun(ctx context.Context, start, finish time.Time, success bool) {}
+
+func TestDAGExecutorParallelism(t *testing.T) {
+	// root -> cm0 ... cm7
+	cases := map[string]struct {
+		parallelism int
+		expectedMax int
+	}{
+		"Unlimited": {
+			parallelism: 0,
+			expectedMax: 8,
+		},
+		"Limited": {
+			parallelism: 2,
+			expectedMax: 2,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			d := dag.New[string]()
+			d.AddVertex(ctx, dag.Root, dag.Root)
+			for i := 0; i < 8; i++ {
+				v := fmt.Sprintf("cm%d", i)
+				d.AddVertex(ctx, v, v)
+				d.Connect(ctx, dag.Root, v)
+			}
+
+			h := &parallelismHandler{}
+			e, err := NewDAGExecutor[string](ctx, d, &Config[string]{
+				Name:    name,
+				From:    dag.Root,
+				Handler: h,
+				Limiter: NewLimiter(tc.parallelism),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !e.Run(ctx) {
+				t.Errorf("want success, got failure")
+			}
+			h.m.Lock()
+			defer h.m.Unlock()
+			if h.max > tc.expectedMax {
+				t.Errorf("want at most %d vertices running concurrently, got: %d", tc.expectedMax, h.max)
+			}
+		})
+	}
+}
+
+// unlimitedHandler blocks the vertex pkg till the vertex cm ran
+type unlimitedHandler struct {
+	cmDone chan struct{}
+}
+
+func (r *unlimitedHandler) BlockRun(ctx context.Context, vertexName string, vertexContext string) error {
+	switch vertexName {
+	case "pkg":
+		select {
+		case <-r.cmDone:
+		case <-time.After(time.Second):
+			return fmt.Errorf("cm did not run while pkg was running")
+		}
+	case "cm":
+		close(r.cmDone)
+	}
+	return nil
+}
+
+func (r *unlimitedHandler) PostRun(ctx context.Context, start, finish time.Time, success bool) {}
+
+func TestDAGExecutorUnlimitedVertex(t *testing.T) {
+	// root -> pkg, root -> cm; pkg does not hold the single slot of the limiter
+	ctx := context.Background()
+	d := dag.New[string]()
+	for _, v := range []string{dag.Root, "pkg", "cm"} {
+		d.AddVertex(ctx, v, v)
+	}
+	d.Connect(ctx, dag.Root, "pkg")
+	d.Connect(ctx, dag.Root, "cm")
+
+	e, err := NewDAGExecutor[string](ctx, d, &Config[string]{
+		Name:    "unlimited",
+		From:    dag.Root,
+		Handler: &unlimitedHandler{cmDone: make(chan struct{})},
+		Limiter: NewLimiter(1),
+		Limited: func(vertexContext string) bool { return vertexContext != "pkg" },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !e.Run(ctx) {
+		t.Errorf("want success, got: %v", e.GetResults())
+	}
+}
@@ -18,6 +18,7 @@ package executor
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -29,8 +30,8 @@ type execContext[T any] struct {
 	vertexName string
 
 	// used to signal the vertex function is done
-	// to the main walk entry
-	doneFnCh chan bool
+	// to the main walk entry, shared by all vertices
+	doneFnCh chan string
 	// used to handle the dependencies between the functions
 	m sync.RWMutex
 	// used to send fn result from the src function
@@ -44,6 +45,8 @@ type execContext[T any] struct {
 	visited time.Time
 	// identifies the time the vertex fn finished
 	finished time.Time
+	// result of the vertex execution
+	result VertexResult
 
 	//vertexContext *rtdag.VertexContext
 	vertexContext T
@@ -52,15 +55,44 @@ type execContext[T any] struct {
 	handler ExecHandler[T]
 }
 
+// VertexResult is the result of the execution of a vertex
 type VertexResult struct {
 	Start   time.Time
 	Finish  time.Time
 	Success bool
+	// Skipped indicates the vertex function did not run, since an upstream
+	// vertex failed or the execution got cancelled
+	Skipped bool
+	Error   error
 	Reason  string
 	Input   any
 	Output  any
 }
 
+// VertexResults are the results of the execution of the vertices of a DAG
+type VertexResults map[string]VertexResult
+
+// Failed returns the sorted names of the vertices whose function failed
+func (r VertexResults) Failed() []string {
+	return r.list(func(result VertexResult) bool { return !result.Success && !result.Skipped })
+}
+
+// Skipped returns the sorted names of the vertices that did not run
+func (r VertexResults) Skipped() []string {
+	return r.list(func(result VertexResult) bool { return result.Skipped })
+}
+
+func (r VertexResults) list(match func(VertexResult) bool) []string {
+	vertexNames := []string{}
+	for vertexName, result := range r {
+		if match(result) {
+			vertexNames = append(vertexNames, vertexName)
+		}
+	}
+	sort.Strings(vertexNames)
+	return vertexNames
+}
+
 func (r *execContext[T]) ListDoneCh() map[string]chan bool {
 	r.m.RLock()
 	defer r.m.RUnlock()
@@ -89,10 +121,17 @@ func (r *execContext[T]) isFinished() bool {
 	return !r.finished.IsZero()
 }
 
-func (r *execContext[T]) updateFinished() {
+func (r *execContext[T]) updateFinished(result VertexResult) {
 	r.m.Lock()
 	defer r.m.Unlock()
-	r.finished = time.Now()
+	r.finished = result.Finish
+	r.result = result
+}
+
+func (r *execContext[T]) getResult() VertexResult {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	return r.result
 }
 
 func (r *execContext[T]) isVisted() bool {
@@ -109,54 +148,68 @@ func (r *execContext[T]) updateVisted() {
 
 // run is executed in a go routine
 func (r *execContext[T]) run(ctx context.Context) {
-	log := log.FromContext(ctx).With("vertexName", r.vertexName)
+	start := time.Now()
 	// execute the handler that runs the function
-	success := r.handler.BlockRun(ctx, r.vertexName, r.vertexContext)
-	//r.finished = time.Now()
-	r.updateFinished()
+	err := r.handler.BlockRun(ctx, r.vertexName, r.vertexContext)
+	result := VertexResult{
+		Start:   start,
+		Finish:  time.Now(),
+		Success: err == nil,
+		Error:   err,
+	}
+	r.signal(ctx, result)
+}
+
+// skip is executed when a dependency failed, the vertex function is not executed
+// but the failure is signalled to the dependent functions and the main walk
+func (r *execContext[T]) skip(ctx context.Context, reason string) {
+	now := time.Now()
+	r.signal(ctx, VertexResult{
+		Start:   now,
+		Finish:  now,
+		Skipped: true,
+		Reason:  reason,
+	})
+}
+
+func (r *execContext[T]) signal(ctx context.Context, result VertexResult) {
+	log := log.FromContext(ctx).With("vertexName", r.vertexName)
+	r.updateFinished(result)
 	doneChs := r.ListDoneCh()
 	downVertices := []string{}
 	for k := range doneChs {
 		downVertices = append(downVertices, k)
 	}
-	log.Debug("block run finished", "downVertices", downVertices, "success", success)
+	log.Debug("block run finished", "downVertices", downVertices, "success", result.Success)
 	// signal to the dependent function the result of the vertex fn execution
 	for vertexName, doneCh := range doneChs {
-		doneCh <- success
+		doneCh <- result.Success
 		close(doneCh)
 		log.Debug("sent done", "from", r.vertexName, "to", vertexName)
 	}
-	// signal the result of the vertex execution to the main walk
-	r.doneFnCh <- success
-	close(r.doneFnCh)
-	log.Debug("done", "success", success)
+	// signal the vertex execution finished to the main walk
+	r.doneFnCh <- r.vertexName
+	log.Debug("done", "success", result.Success)
 }
 
-func (r *execContext[T]) waitDependencies(ctx context.Context) bool {
+// waitDependencies returns the sorted names of the dependencies that did not succeed
+func (r *execContext[T]) waitDependencies(ctx context.Context) []string {
 	// for each dependency wait till a it completed, either through
 	// the dependency Channel or cancel or
 	log := log.FromContext(ctx).With("vertexName", r.vertexName)
 	log.Debug("wait dependencies", "deps", r.deps)
-DepSatisfied:
+	// we wait for all dependencies, even if one failed, to ensure
+	// the dependencies are not blocked signalling their result
+	failed := []string{}
 	for depVertexName, depCh := range r.depChs {
-		//DepSatisfied:
-		for {
-			select {
-			case d, ok := <-depCh:
-				log.Debug("rcvd done", "from", depVertexName, "to", r.vertexName, "success", d, "ok", ok)
-				if ok {
-					continue DepSatisfied
-				}
-				if !d {
-					// dependency failed
-					return false
-				}
-				continue DepSatisfied
-			case <-time.After(time.Second * 5):
-				log.Debug("rwait timeout, waiting", "for", depVertexName)
-			}
+		d, ok := <-depCh
+		log.Debug("rcvd done", "from", depVertexName, "to", r.vertexName, "success", d, "ok", ok)
+		if ok && !d {
+			// dependency failed
+			failed = append(failed, depVertexName)
 		}
 	}
-	log.Debug("finished waiting ...")
-	return true
+	sort.Strings(failed)
+	log.Debug("finished waiting ...", "failed", failed)
+	return failed
 }
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import "context"
+
+// Limiter bounds the amount of work that runs concurrently, a nil Limiter
+// does not impose a limit
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter returns a Limiter with n slots, when n <= 0 no limit applies
+// and nil is returned
+func NewLimiter(n int) *Limiter {
+	if n <= 0 {
+		return nil
+	}
+	return &Limiter{slots: make(chan struct{}, n)}
+}
+
+// Acquire blocks till a slot is available or the context is cancelled
+func (r *Limiter) Acquire(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	select {
+	case r.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires a slot when one is available w/o blocking
+func (r *Limiter) TryAcquire() bool {
+	if r == nil {
+		return true
+	}
+	select {
+	case r.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release frees a slot acquired with Acquire or TryAcquire
+func (r *Limiter) Release() {
+	if r == nil {
+		return
+	}
+	<-r.slots
+}
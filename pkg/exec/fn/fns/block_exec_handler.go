@@ -14,6 +14,7 @@ import (
 	"github.com/henderiw/store"
 	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/executor"
 	"github.com/kform-dev/kform/pkg/recorder"
 	"github.com/kform-dev/kform/pkg/recorder/diag"
 	"github.com/kform-dev/kform/pkg/render2/celrenderer"
@@ -29,6 +30,7 @@ func NewExecHandler(ctx context.Context, cfg *Config) *ExecHandler {
 		VarStore:        cfg.VarStore,
 		OutputStore:     cfg.OutputStore,
 		Recorder:        cfg.Recorder,
+		Limiter:         cfg.Limiter,
 		fnsMap: NewMap(ctx, &Config{
 			Kind:              cfg.Kind,
 			RootPackageName:   cfg.RootPackageName,
@@ -41,6 +43,10 @@ func NewExecHandler(ctx context.Context, cfg *Config) *ExecHandler {
 			Resources:         cfg.Resources,
 			DryRun:            cfg.DryRun,
 			Destroy:           cfg.Destroy,
+			KeepGoing:         cfg.KeepGoing,
+			Limiter:           cfg.Limiter,
+			ProviderLimiters:  cfg.ProviderLimiters,
+			Waiter:            cfg.Waiter,
 		}),
 	}
 }
@@ -52,7 +58,10 @@ type ExecHandler struct {
 	VarStore        store.Storer[data.VarData]
 	OutputStore     store.Storer[data.BlockData]
 	Recorder        recorder.Recorder[diag.Diagnostic]
-	fnsMap          Map
+	// Limiter is shared with the DAG executor, which acquires a slot per vertex
+	// except for the package vertices
+	Limiter *executor.Limiter
+	fnsMap  Map
 }
 
 // PostRun records the overall result of the package execHandler
@@ -66,20 +75,19 @@ func (r *ExecHandler) PostRun(ctx context.Context, start, stop time.Time, succes
 	}
 }
 
-func (r *ExecHandler) BlockRun(ctx context.Context, vertexName string, vctx *types.VertexContext) bool {
+func (r *ExecHandler) BlockRun(ctx context.Context, vertexName string, vctx *types.VertexContext) error {
 	log := log.FromContext(ctx).With("vertexContext", vctx.String())
 	log.Debug("run block start...")
 	recorder := r.Recorder
 	start := time.Now()
-	success := true
-	if err := r.runInstances(ctx, vctx); err != nil {
+	err := r.runInstances(ctx, vctx)
+	if err != nil {
 		recorder.Record(diag.FromErrWithTimeContext(vctx.String(), start, fmt.Errorf("failed block total run err: %s", err.Error())))
-		success = false
 	} else {
 		recorder.Record(diag.Success(vctx.String(), start, "block total run"))
 	}
-	log.Debug("run block finished...", "success", success)
-	return success
+	log.Debug("run block finished...", "success", err == nil)
+	return err
 }
 
 func (r *ExecHandler) runInstances(ctx context.Context, vctx *types.VertexContext) error {
@@ -104,11 +112,14 @@ func (r *ExecHandler) runInstances(ctx context.Context, vctx *types.VertexContex
 			// we treat a singleton in the same way as count -> count.index will not be used based on our syntax checks
 			localVars[kformv1alpha1.LoopKeyCountIndex] = item.key
 		}
-		wg.Add(1)
-		//g.Go(func() error {
-		go func(errCh chan error) {
-			defer wg.Done()
+		runInstance := func() {
 			start := time.Now()
+			// the preconditions are validated before the block instance is run
+			if err := r.checkCondition(ctx, vctx, localVars, conditionPre); err != nil {
+				recorder.Record(diag.FromErrWithTimeContext(vctx.String(), start, err))
+				errCh <- err
+				return
+			}
 			// lookup the blockType in the map and run the block instance
 			if err := r.fnsMap.Run(ctx, vctx, localVars); err != nil {
 				log.Debug("run result", "error", err)
@@ -117,6 +128,12 @@ func (r *ExecHandler) runInstances(ctx context.Context, vctx *types.VertexContex
 				return
 			}
 			log.Debug("run result", "error", err)
+			// the postconditions are validated against the result of the block instance
+			if err := r.checkCondition(ctx, vctx, localVars, conditionPost); err != nil {
+				recorder.Record(diag.FromErrWithTimeContext(vctx.String(), start, err))
+				errCh <- err
+				return
+			}
 			recorder.Record(diag.Success(vctx.String(), start, "block instance run"))
 			select {
 			case <-ctx.Done():
@@ -128,7 +145,28 @@ func (r *ExecHandler) runInstances(ctx context.Context, vctx *types.VertexContex
 				return
 				//return nil
 			}
-		}(errCh)
+		}
+		// package instances dont need a slot, since the vertices of their DAG acquire the slots
+		if vctx.BlockType == kformv1alpha1.BlockTYPE_PACKAGE {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				runInstance()
+			}()
+			continue
+		}
+		// the vertex holds a slot of the limiter, additional instances run concurrently
+		// when a slot is available, otherwise they run sequentially in the slot of the vertex
+		if !r.Limiter.TryAcquire() {
+			runInstance()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.Limiter.Release()
+			runInstance()
+		}()
 	}
 	go func() {
 		wg.Wait()
@@ -144,6 +182,91 @@ func (r *ExecHandler) runInstances(ctx context.Context, vctx *types.VertexContex
 	return errm
 }
 
+const (
+	conditionPre  = "precondition"
+	conditionPost = "postcondition"
+	// conditionKeySelf references the result of the block instance in a postcondition
+	conditionKeySelf = "self"
+)
+
+// checkCondition evaluates the cel expression of the pre- or postcondition of a block instance,
+// the condition fails when the expression does not evaluate to true. For postconditions
+// the result of the block instance is available as self.
+func (r *ExecHandler) checkCondition(ctx context.Context, vctx *types.VertexContext, localVars map[string]any, kind string) error {
+	if vctx.Attributes == nil {
+		return nil
+	}
+	expr := vctx.Attributes.PreCondition
+	if kind == conditionPost {
+		expr = vctx.Attributes.PostCondition
+	}
+	if expr == "" {
+		return nil
+	}
+	index := localVars[kformv1alpha1.LoopKeyItemsIndex]
+	vars := make(map[string]any, len(localVars)+1)
+	for k, v := range localVars {
+		vars[k] = v
+	}
+	if kind == conditionPost {
+		vars[conditionKeySelf] = r.getInstanceResult(vctx, localVars)
+	}
+	v, err := celrenderer.New(r.VarStore, vars).RenderString(ctx, expr)
+	if err != nil {
+		return fmt.Errorf("block %s[%v] %s %q cannot be evaluated, err: %s", vctx.BlockName, index, kind, expr, err.Error())
+	}
+	ok, err := isTrue(v)
+	if err != nil {
+		return fmt.Errorf("block %s[%v] %s %q %s", vctx.BlockName, index, kind, expr, err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("block %s[%v] %s %q failed", vctx.BlockName, index, kind, expr)
+	}
+	return nil
+}
+
+// getInstanceResult returns the result of the block instance from the varStore
+func (r *ExecHandler) getInstanceResult(vctx *types.VertexContext, localVars map[string]any) any {
+	varData, err := r.VarStore.Get(store.ToKey(vctx.BlockName))
+	if err != nil {
+		return nil
+	}
+	index, ok := localVars[kformv1alpha1.LoopKeyItemsIndex].(int)
+	if !ok {
+		index = 0
+	}
+	getItem := func(items []any) any {
+		if index < 0 || index >= len(items) {
+			return nil
+		}
+		return items[index]
+	}
+	if items, ok := varData[data.DummyKey]; ok {
+		return getItem(items)
+	}
+	// packages store their outputs per key
+	result := map[string]any{}
+	for k, items := range varData {
+		result[k] = getItem(items)
+	}
+	return result
+}
+
+func isTrue(v any) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("must evaluate to a bool, got: %s", v)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("must evaluate to a bool, got: %v", reflect.TypeOf(v))
+	}
+}
+
 type item struct {
 	key any
 	val any
@@ -167,18 +290,18 @@ func (r *ExecHandler) getLoopItems(ctx context.Context, attr *kformv1alpha1.Attr
 					return isForEach, items, fmt.Errorf("render loop forEach failed: err: %s", err)
 				}
 			}
-			log.Debug("getLoopItems forEach render output", "value type", reflect.TypeOf(v), "value", v)
+			log.Debug("getLoopItems forEach render output", "value type", reflect.TypeOf(v), "value", redact(attr, v))
 			switch v := v.(type) {
 			case []string:
 				// in a list we return key = int, val = any
 				for k, v := range v {
-					log.Debug("getLoopItems forEach insert item", "k", k, "v", v)
+					log.Debug("getLoopItems forEach insert item", "k", redact(attr, k), "v", redact(attr, v))
 					items.Add(k, item{key: k, val: v})
 				}
 			case []any:
 				// in a list we return key = int, val = any
 				for k, v := range v {
-					log.Debug("getLoopItems forEach insert item", "k", k, "v", v)
+					log.Debug("getLoopItems forEach insert item", "k", redact(attr, k), "v", redact(attr, v))
 					items.Add(k, item{key: k, val: v})
 				}
 			case map[any]any:
@@ -10,6 +10,7 @@ import (
 	"github.com/kform-dev/kform-plugin/plugin"
 	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/executor"
 	"github.com/kform-dev/kform/pkg/exec/fn"
 	"github.com/kform-dev/kform/pkg/recorder"
 	"github.com/kform-dev/kform/pkg/recorder/diag"
@@ -52,6 +53,14 @@ type Config struct {
 	Resources store.Storer[store.Storer[data.BlockData]]
 	DryRun    bool
 	Destroy   bool
+	// KeepGoing runs the independent blocks after a block failed
+	KeepGoing bool
+	// Limiter bounds the block instances that run concurrently accross the DAG(s)
+	Limiter *executor.Limiter
+	// ProviderLimiters bound the concurrent requests per provider
+	ProviderLimiters map[string]*executor.Limiter
+	// Waiter waits till the applied resources are ready, nil does not wait
+	Waiter *Waiter
 }
 
 func NewMap(ctx context.Context, cfg *Config) Map {
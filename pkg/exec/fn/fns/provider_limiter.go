@@ -0,0 +1,63 @@
+package fns
+
+import (
+	"context"
+
+	"github.com/kform-dev/kform-plugin/kfprotov1/kfplugin1"
+	"github.com/kform-dev/kform-plugin/plugin"
+	"github.com/kform-dev/kform/pkg/exec/executor"
+)
+
+// limitProvider returns a provider that bounds the concurrent data source and resource
+// requests with the limiter, w/o a limiter the provider is returned as is
+func limitProvider(provider plugin.Provider, limiter *executor.Limiter) plugin.Provider {
+	if limiter == nil {
+		return provider
+	}
+	return &limitedProvider{Provider: provider, limiter: limiter}
+}
+
+type limitedProvider struct {
+	plugin.Provider
+	limiter *executor.Limiter
+}
+
+func (r *limitedProvider) ReadDataSource(ctx context.Context, req *kfplugin1.ReadDataSource_Request) (*kfplugin1.ReadDataSource_Response, error) {
+	if err := r.limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer r.limiter.Release()
+	return r.Provider.ReadDataSource(ctx, req)
+}
+
+func (r *limitedProvider) ListDataSource(ctx context.Context, req *kfplugin1.ListDataSource_Request) (*kfplugin1.ListDataSource_Response, error) {
+	if err := r.limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer r.limiter.Release()
+	return r.Provider.ListDataSource(ctx, req)
+}
+
+func (r *limitedProvider) CreateResource(ctx context.Context, req *kfplugin1.CreateResource_Request) (*kfplugin1.CreateResource_Response, error) {
+	if err := r.limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer r.limiter.Release()
+	return r.Provider.CreateResource(ctx, req)
+}
+
+func (r *limitedProvider) UpdateResource(ctx context.Context, req *kfplugin1.UpdateResource_Request) (*kfplugin1.UpdateResource_Response, error) {
+	if err := r.limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer r.limiter.Release()
+	return r.Provider.UpdateResource(ctx, req)
+}
+
+func (r *limitedProvider) DeleteResource(ctx context.Context, req *kfplugin1.DeleteResource_Request) (*kfplugin1.DeleteResource_Response, error) {
+	if err := r.limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer r.limiter.Release()
+	return r.Provider.DeleteResource(ctx, req)
+}
@@ -38,7 +38,7 @@ func (r *input) Run(ctx context.Context, vctx *types.VertexContext, localVars ma
 			return err
 		}
 		r.varStore.Create(store.ToKey(vctx.BlockName), varData)
-		log.Debug("input", "value", vctx.Data)
+		log.Debug("input", "value", redact(vctx.Attributes, vctx.Data))
 	}
 	log.Debug("run block instance finished...")
 	return nil
@@ -63,6 +63,10 @@ func (r *localOrOutput) Run(ctx context.Context, vctx *types.VertexContext, loca
 		annotations := rn.GetAnnotations()
 		annotations[kformv1alpha1.KformAnnotationKey_PATH] = vctx.FileName
 		annotations[kformv1alpha1.KformAnnotationKey_INDEX] = vctx.Index
+		// the output is masked by the writer when derived from a sensitive block
+		if vctx.Attributes.IsSensitive() {
+			annotations[kformv1alpha1.KformAnnotationKey_SENSITIVE] = "true"
+		}
 		rn.SetAnnotations(annotations)
 		log.Debug("update blockStore start...")
 		if err := data.UpdateBlockStoreEntry(ctx, r.outputStore, vctx.BlockName, rn, localVars); err != nil {
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/henderiw/logger/log"
 	"github.com/henderiw/store"
 	"github.com/henderiw/store/memory"
 	"github.com/kform-dev/kform-plugin/plugin"
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
 	"github.com/kform-dev/kform/pkg/dag"
 	"github.com/kform-dev/kform/pkg/data"
 	"github.com/kform-dev/kform/pkg/exec/executor"
@@ -30,6 +32,10 @@ func NewPackageFn(cfg *Config) fn.BlockInstanceRunner {
 		resources:         cfg.Resources,
 		dryRun:            cfg.DryRun,
 		destroy:           cfg.Destroy,
+		keepGoing:         cfg.KeepGoing,
+		limiter:           cfg.Limiter,
+		providerLimiters:  cfg.ProviderLimiters,
+		waiter:            cfg.Waiter,
 	}
 }
 
@@ -46,6 +52,10 @@ type pkg struct {
 	resources         store.Storer[store.Storer[data.BlockData]]
 	dryRun            bool
 	destroy           bool
+	keepGoing         bool
+	limiter           *executor.Limiter
+	providerLimiters  map[string]*executor.Limiter
+	waiter            *Waiter
 }
 
 /*
@@ -95,6 +105,14 @@ func (r *pkg) Run(ctx context.Context, vctx *types.VertexContext, localVars map[
 	e, err := executor.NewDAGExecutor[*types.VertexContext](ctx, vctx.DAG, &executor.Config[*types.VertexContext]{
 		Name: vctx.BlockName,
 		From: dag.Root,
+		// resources are deleted in the reverse order of their creation
+		Reverse: r.destroy,
+		Limiter: r.limiter,
+		// a package runs its own DAG, which acquires the slots of the limiter per vertex
+		Limited: func(vctx *types.VertexContext) bool {
+			return vctx.BlockType != kformv1alpha1.BlockTYPE_PACKAGE
+		},
+		KeepGoing: r.keepGoing,
 		Handler: NewExecHandler(ctx, &Config{
 			Kind: r.kind,
 			// provider should not be set, since provider dag is not hierarchical
@@ -109,6 +127,10 @@ func (r *pkg) Run(ctx context.Context, vctx *types.VertexContext, localVars map[
 			Resources:         r.resources,
 			DryRun:            r.dryRun,
 			Destroy:           r.destroy,
+			KeepGoing:         r.keepGoing,
+			Limiter:           r.limiter,
+			ProviderLimiters:  r.providerLimiters,
+			Waiter:            r.waiter,
 		}),
 	})
 	if err != nil {
@@ -122,6 +144,9 @@ func (r *pkg) Run(ctx context.Context, vctx *types.VertexContext, localVars map[
 			// TODO output prefix needs to be replaced with mixin.packagename.<outputvariable>
 			r.outputStore.Create(k, bd)
 		})
+	} else if skipped := e.GetResults().Skipped(); len(skipped) != 0 {
+		// report the blocks that did not run due to the failure, the run already failed
+		r.recorder.Record(diag.DiagErrorfWithContext(vctx.String(), "blocks not run after failure: %s", strings.Join(skipped, ", ")))
 	}
 	return nil
 }
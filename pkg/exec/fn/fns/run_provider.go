@@ -62,7 +62,7 @@ func (r *provider) Run(ctx context.Context, vctx *types.VertexContext, localVars
 		log.Error("cannot store provider config", "error", err.Error())
 		return err
 	}
-	log.Debug("providerConfig", "config", string(b))
+	log.Debug("providerConfig", "config", redact(vctx.Attributes, b))
 	// get the provider for initialization
 	p, err := r.providers.Get(store.ToKey(vctx.BlockName))
 	if err != nil {
@@ -11,8 +11,11 @@ import (
 	"github.com/kform-dev/kform-plugin/kfprotov1/kfplugin1"
 	"github.com/kform-dev/kform-plugin/plugin"
 	"github.com/kform-dev/kform-sdk-go/pkg/diag"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
 	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/diff"
+	"github.com/kform-dev/kform/pkg/exec/executor"
 	"github.com/kform-dev/kform/pkg/exec/fn"
 	"github.com/kform-dev/kform/pkg/render2/celrenderer"
 	"github.com/kform-dev/kform/pkg/syntax/types"
@@ -30,6 +33,8 @@ func NewResourceFn(cfg *Config) fn.BlockInstanceRunner {
 		resources:         cfg.Resources,
 		dryRun:            cfg.DryRun,
 		destroy:           cfg.Destroy,
+		providerLimiters:  cfg.ProviderLimiters,
+		waiter:            cfg.Waiter,
 	}
 }
 
@@ -42,6 +47,8 @@ type resource struct {
 	resources         store.Storer[store.Storer[data.BlockData]]
 	dryRun            bool
 	destroy           bool
+	providerLimiters  map[string]*executor.Limiter
+	waiter            *Waiter
 }
 
 func (r *resource) Run(ctx context.Context, vctx *types.VertexContext, localVars map[string]any) error {
@@ -90,10 +97,11 @@ func (r *resource) Run(ctx context.Context, vctx *types.VertexContext, localVars
 			log.Error("provider not initialized", "provider", vctx.Attributes.Provider)
 			return fmt.Errorf("provider %s not initialized for block: %s", vctx.Attributes.Provider, vctx.BlockName)
 		}
+		provider = limitProvider(provider, r.providerLimiters[vctx.Attributes.Provider])
 		name := strings.Split(vctx.BlockName, ".")[0]
 		switch vctx.BlockType {
 		case kformv1alpha1.BlockTYPE_DATA:
-			log.Debug("resource data", "json req", string(b))
+			log.Debug("resource data", "json req", redact(vctx.Attributes, b))
 			b, err = r.get(ctx, provider, name, b)
 			if err != nil {
 				// for inventory read we can ignore read errors
@@ -105,12 +113,23 @@ func (r *resource) Run(ctx context.Context, vctx *types.VertexContext, localVars
 				return err
 			}
 		case kformv1alpha1.BlockTYPE_RESOURCE:
-			log.Debug("resource data", "json req", string(b))
+			log.Debug("resource data", "json req", redact(vctx.Attributes, b))
 			if r.destroy {
 				// we already did a get before
 				if err := r.delete(ctx, provider, name, b); err != nil {
 					return err
 				}
+				// the deleted resources are recorded, such that a failed destroy run
+				// knows which resources no longer exist
+				pkgStore, err := r.resources.Get(store.ToKey(r.rootPackageName))
+				if err != nil {
+					return err
+				}
+				localVars[kformv1alpha1.LoopKeyItemsTotal] = vctx.Data.Len()
+				localVars[kformv1alpha1.LoopKeyItemsIndex] = idx
+				if err := data.UpdateBlockStoreEntry(ctx, pkgStore, vctx.BlockName, rn, localVars); err != nil {
+					return err
+				}
 			} else {
 				rb, err := r.get(ctx, provider, name, b)
 				if err != nil {
@@ -118,21 +137,25 @@ func (r *resource) Run(ctx context.Context, vctx *types.VertexContext, localVars
 						log.Error("resource error get", "error", err.Error())
 						return err
 					}
-					log.Debug("not found -> create", "data", string(b))
+					log.Debug("not found -> create", "data", redact(vctx.Attributes, b))
 					b, err = r.create(ctx, provider, name, b)
 					if err != nil {
 						// no need for log as this is a duplicate
 						return err
 					}
-					log.Debug("create resp", "data", string(b))
+					log.Debug("create resp", "data", redact(vctx.Attributes, b))
 				} else {
-					log.Debug("found -> update", "data", string(b))
+					log.Debug("found -> update", "data", redact(vctx.Attributes, b))
+					b, err = r.ignoreChanges(vctx, b, rb)
+					if err != nil {
+						return err
+					}
 					b, err = r.update(ctx, provider, name, b, rb)
 					if err != nil {
 						// no need for log as this is a duplicate
 						return err
 					}
-					log.Debug("update resp", "data", string(b))
+					log.Debug("update resp", "data", redact(vctx.Attributes, b))
 				}
 			}
 		case kformv1alpha1.BlockTYPE_LIST:
@@ -162,7 +185,7 @@ func (r *resource) Run(ctx context.Context, vctx *types.VertexContext, localVars
 				return err
 			}
 
-			log.Debug("data response", "resp", string(b))
+			log.Debug("data response", "resp", redact(vctx.Attributes, b))
 
 			if err := data.UpdateVarStore(ctx, r.varStore, vctx.BlockName, v, localVars); err != nil {
 				return fmt.Errorf("update vars failed failed for blockName %s, err: %s", vctx.BlockName, err.Error())
@@ -179,8 +202,18 @@ func (r *resource) Run(ctx context.Context, vctx *types.VertexContext, localVars
 				if err != nil {
 					return err
 				}
-				// we need to fake the count for inventory dagRun read
+				// for an inventory read we store the object as it exists in the cluster
+				// such that it can be compared with the newly rendered resources
 				if r.kind == DagRunInventory && vctx.BlockType == kformv1alpha1.BlockTYPE_DATA {
+					rn, err = yaml.FromMap(v)
+					if err != nil {
+						log.Error("cannot convert resp", "error", err.Error())
+						return err
+					}
+				}
+				// we need to fake the count for inventory dagRuns since the block
+				// holds all the resources of the inventory or the saved plan
+				if r.kind == DagRunInventory {
 					localVars[kformv1alpha1.LoopKeyItemsTotal] = vctx.Data.Len()
 					localVars[kformv1alpha1.LoopKeyItemsIndex] = idx
 				}
@@ -188,6 +221,21 @@ func (r *resource) Run(ctx context.Context, vctx *types.VertexContext, localVars
 					return err
 				}
 			}
+
+			// the resource is recorded as actuated, the vertex only finishes when the
+			// resource is ready such that the dependent blocks wait for it
+			if vctx.BlockType == kformv1alpha1.BlockTYPE_RESOURCE && !r.dryRun && r.waiter != nil {
+				ref, err := invv1alpha1.GetObjectReference(rn)
+				if err != nil {
+					return err
+				}
+				req := b
+				if err := r.waiter.Wait(ctx, r.rootPackageName, ref, func(ctx context.Context) ([]byte, error) {
+					return r.get(ctx, provider, name, req)
+				}); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -195,6 +243,29 @@ func (r *resource) Run(ctx context.Context, vctx *types.VertexContext, localVars
 	return nil
 }
 
+// ignoreChanges sets the fields of the lifecycle ignoreChanges in the new object to the
+// values of the existing object, such that the update does not change them
+func (r *resource) ignoreChanges(vctx *types.VertexContext, newb, oldb []byte) ([]byte, error) {
+	lifecycle, err := vctx.Attributes.GetLifeCycle()
+	if err != nil {
+		return nil, err
+	}
+	if len(lifecycle.GetIgnoreChanges()) == 0 {
+		return newb, nil
+	}
+	var newObj, oldObj map[string]any
+	if err := json.Unmarshal(newb, &newObj); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(oldb, &oldObj); err != nil {
+		return nil, err
+	}
+	if err := diff.IgnoreChanges(oldObj, newObj, lifecycle.GetIgnoreChanges()); err != nil {
+		return nil, fmt.Errorf("cannot ignore changes for %s, err: %s", vctx.BlockName, err.Error())
+	}
+	return json.Marshal(newObj)
+}
+
 func (r *resource) get(ctx context.Context, provider plugin.Provider, name string, b []byte) ([]byte, error) {
 	log := log.FromContext(ctx)
 	resp, err := provider.ReadDataSource(ctx, &kfplugin1.ReadDataSource_Request{
@@ -0,0 +1,17 @@
+package fns
+
+import (
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+)
+
+// redact returns the value as it can be logged, the values of sensitive
+// blocks are replaced by the sensitive mask
+func redact(attrs *kformv1alpha1.Attributes, v any) any {
+	if attrs.IsSensitive() {
+		return kformv1alpha1.SensitiveMask
+	}
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
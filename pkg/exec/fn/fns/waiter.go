@@ -0,0 +1,124 @@
+package fns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/henderiw/logger/log"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+const defaultWaitInterval = 2 * time.Second
+
+// ReconcileResult is the observed reconciliation of an applied object
+type ReconcileResult struct {
+	Status invv1alpha1.ReconcileStatus
+	Time   metav1.Time
+}
+
+type waitKey struct {
+	pkgName string
+	ref     invv1alpha1.ObjectReference
+}
+
+// Waiter waits till the applied objects are ready and records the outcome per object,
+// a nil Waiter does not wait
+type Waiter struct {
+	timeout  time.Duration
+	interval time.Duration
+
+	m       sync.RWMutex
+	results map[waitKey]ReconcileResult
+}
+
+// NewWaiter returns a Waiter that waits up to timeout per object, when timeout <= 0
+// the Waiter waits till the run is cancelled
+func NewWaiter(timeout time.Duration) *Waiter {
+	return &Waiter{
+		timeout:  timeout,
+		interval: defaultWaitInterval,
+		results:  map[waitKey]ReconcileResult{},
+	}
+}
+
+// Wait polls the object with the getFn till its status is current. The status is computed
+// kstatus-style, such that e.g. Deployments are rolled out, Jobs are complete and CRDs are
+// established. An error is returned when the object failed or did not get ready in time.
+func (r *Waiter) Wait(ctx context.Context, pkgName string, ref invv1alpha1.ObjectReference, getFn func(ctx context.Context) ([]byte, error)) error {
+	if r == nil {
+		return nil
+	}
+	log := log.FromContext(ctx).With("object", ref.String())
+
+	waitCtx := ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		b, err := getFn(waitCtx)
+		if err != nil {
+			log.Debug("cannot get object status", "err", err.Error())
+		} else {
+			result, err := computeStatus(b)
+			if err != nil {
+				log.Debug("cannot compute object status", "err", err.Error())
+			} else {
+				switch result.Status {
+				case kstatus.CurrentStatus:
+					r.record(pkgName, ref, invv1alpha1.ReconcileSucceeded)
+					return nil
+				case kstatus.FailedStatus:
+					r.record(pkgName, ref, invv1alpha1.ReconcileFailed)
+					return fmt.Errorf("%s failed to reconcile: %s", ref.String(), result.Message)
+				}
+				log.Debug("waiting for object", "status", result.Status, "msg", result.Message)
+			}
+		}
+		select {
+		case <-waitCtx.Done():
+			// a cancelled run did not observe the reconciliation of the object
+			if ctx.Err() != nil || !errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+				return ctx.Err()
+			}
+			r.record(pkgName, ref, invv1alpha1.ReconcileTimeout)
+			return fmt.Errorf("timeout after %s waiting for %s to reconcile", r.timeout, ref.String())
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetResult returns the reconcile result of the object, if the Waiter waited for it
+func (r *Waiter) GetResult(pkgName string, ref invv1alpha1.ObjectReference) (ReconcileResult, bool) {
+	if r == nil {
+		return ReconcileResult{}, false
+	}
+	r.m.RLock()
+	defer r.m.RUnlock()
+	result, ok := r.results[waitKey{pkgName: pkgName, ref: ref}]
+	return result, ok
+}
+
+func (r *Waiter) record(pkgName string, ref invv1alpha1.ObjectReference, status invv1alpha1.ReconcileStatus) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.results[waitKey{pkgName: pkgName, ref: ref}] = ReconcileResult{Status: status, Time: metav1.Now()}
+}
+
+func computeStatus(b []byte) (*kstatus.Result, error) {
+	// the unstructured decoding retains the integer fields, which kstatus requires
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return kstatus.Compute(u)
+}
@@ -0,0 +1,67 @@
+package fns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+)
+
+func TestWaiter(t *testing.T) {
+	deployment := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"a","namespace":"default","generation":1},"spec":{"replicas":1}}`
+	deploymentReady := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"a","namespace":"default","generation":1},"spec":{"replicas":1},` +
+		`"status":{"observedGeneration":1,"replicas":1,"updatedReplicas":1,"readyReplicas":1,"availableReplicas":1,` +
+		`"conditions":[{"type":"Available","status":"True"},{"type":"Progressing","status":"True","reason":"NewReplicaSetAvailable"}]}}`
+	jobFailed := `{"apiVersion":"batch/v1","kind":"Job","metadata":{"name":"a","namespace":"default"},"spec":{},` +
+		`"status":{"conditions":[{"type":"Failed","status":"True","message":"backoff limit exceeded"}]}}`
+
+	cases := map[string]struct {
+		objects     []string
+		timeout     time.Duration
+		expectedErr bool
+		expected    invv1alpha1.ReconcileStatus
+	}{
+		"Ready": {
+			objects:  []string{deployment, deploymentReady},
+			timeout:  time.Second,
+			expected: invv1alpha1.ReconcileSucceeded,
+		},
+		"Failed": {
+			objects:     []string{jobFailed},
+			timeout:     time.Second,
+			expectedErr: true,
+			expected:    invv1alpha1.ReconcileFailed,
+		},
+		"Timeout": {
+			objects:     []string{deployment},
+			timeout:     50 * time.Millisecond,
+			expectedErr: true,
+			expected:    invv1alpha1.ReconcileTimeout,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := NewWaiter(tc.timeout)
+			w.interval = 10 * time.Millisecond
+			ref := invv1alpha1.ObjectReference{Kind: "Deployment", Name: "a", Namespace: "default"}
+			polls := 0
+			err := w.Wait(context.Background(), "root", ref, func(ctx context.Context) ([]byte, error) {
+				obj := tc.objects[min(polls, len(tc.objects)-1)]
+				polls++
+				return []byte(obj), nil
+			})
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("want error %t, got: %v", tc.expectedErr, err)
+			}
+			result, ok := w.GetResult("root", ref)
+			if !ok {
+				t.Fatalf("want result, got none")
+			}
+			if result.Status != tc.expected {
+				t.Errorf("want status %s, got: %s", tc.expected, result.Status)
+			}
+		})
+	}
+}
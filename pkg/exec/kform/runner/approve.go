@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	planv1alpha1 "github.com/kform-dev/kform/apis/plan/v1alpha1"
+	"github.com/kform-dev/kform/pkg/exec/diff"
+	"golang.org/x/term"
+)
+
+var actionSymbols = map[planv1alpha1.Action]string{
+	planv1alpha1.ActionCreate: "+",
+	planv1alpha1.ActionUpdate: "~",
+	planv1alpha1.ActionDelete: "-",
+}
+
+// approve prints the plan and asks the user to confirm the operation (Apply or Destroy)
+// when auto-approve is set the prompt is skipped; when the input is not
+// a terminal the plan is printed but we refuse to proceed since nobody
+// can confirm the plan
+func (r *runner) approve(plan *planv1alpha1.Plan, differ *diff.Differ, operation string) (bool, error) {
+	if r.cfg.AutoApprove {
+		return true, nil
+	}
+	in := r.cfg.IOStreams.In
+	out := r.out()
+
+	printPlan(out, plan)
+	differ.PrintSummary(out)
+	if !isTerminal(in) {
+		return false, fmt.Errorf("cannot ask for approval in non-interactive mode, use --auto-approve")
+	}
+	fmt.Fprintf(out, "\nDo you want to perform these actions?\n  Only 'yes' will be accepted to approve.\n\n  Enter a value: ")
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if strings.TrimSpace(answer) != "yes" {
+		fmt.Fprintf(out, "\n%s cancelled.\n", operation)
+		return false, nil
+	}
+	return true, nil
+}
+
+// isTerminal returns true when the input is a terminal
+var isTerminal = func(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	if !ok {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// printPlan prints the changed resources of the plan per package with the action
+// that will be performed on them, unchanged resources are skipped
+func printPlan(w io.Writer, plan *planv1alpha1.Plan) {
+	fmt.Fprintf(w, "Kform will perform the following actions:\n")
+	pkgNames := make([]string, 0, len(plan.Spec.Packages))
+	for pkgName := range plan.Spec.Packages {
+		pkgNames = append(pkgNames, pkgName)
+	}
+	sort.Strings(pkgNames)
+	for _, pkgName := range pkgNames {
+		pkgPlan := plan.Spec.Packages[pkgName]
+		blockNames := make([]string, 0, len(pkgPlan.Resources))
+		for blockName := range pkgPlan.Resources {
+			blockNames = append(blockNames, blockName)
+		}
+		sort.Strings(blockNames)
+		header := false
+		for _, blockName := range blockNames {
+			for idx, resource := range pkgPlan.Resources[blockName] {
+				if resource.Action == planv1alpha1.ActionNoOp {
+					continue
+				}
+				if !header {
+					fmt.Fprintf(w, "\npackage: %s\n", pkgName)
+					header = true
+				}
+				fmt.Fprintf(w, "  %s %s[%d] %s\n", actionSymbols[resource.Action], blockName, idx, resourceRef(resource.Object))
+			}
+		}
+	}
+}
+
+func resourceRef(obj map[string]any) string {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	var name, namespace string
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		name, _ = metadata["name"].(string)
+		namespace, _ = metadata["namespace"].(string)
+	}
+	if namespace != "" {
+		return fmt.Sprintf("%s/%s %s/%s", apiVersion, kind, namespace, name)
+	}
+	return fmt.Sprintf("%s/%s %s", apiVersion, kind, name)
+}
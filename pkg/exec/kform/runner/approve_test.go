@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	planv1alpha1 "github.com/kform-dev/kform/apis/plan/v1alpha1"
+	"github.com/kform-dev/kform/pkg/exec/diff"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestApprove(t *testing.T) {
+	cases := map[string]struct {
+		autoApprove      bool
+		terminal         bool
+		answer           string
+		operation        string
+		expectedApproved bool
+		expectedErr      bool
+		expectedOut      []string
+		unexpectedOut    []string
+	}{
+		"AutoApprove": {
+			autoApprove:      true,
+			operation:        "Apply",
+			expectedApproved: true,
+		},
+		"NonInteractive": {
+			// the plan is printed before the approval is refused
+			operation:     "Apply",
+			expectedErr:   true,
+			expectedOut:   []string{"Kform will perform the following actions:", "package: root", "+ kubernetes_manifest.cm[0] v1/ConfigMap default/cm1", "Plan:"},
+			unexpectedOut: []string{"cm2"},
+		},
+		"Approved": {
+			terminal:         true,
+			answer:           "yes\n",
+			operation:        "Apply",
+			expectedApproved: true,
+		},
+		"ApplyCancelled": {
+			terminal:    true,
+			answer:      "no\n",
+			operation:   "Apply",
+			expectedOut: []string{"Apply cancelled."},
+		},
+		"DestroyCancelled": {
+			terminal:    true,
+			answer:      "no\n",
+			operation:   "Destroy",
+			expectedOut: []string{"Destroy cancelled."},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			isTerminalFn := isTerminal
+			defer func() { isTerminal = isTerminalFn }()
+			isTerminal = func(in io.Reader) bool { return tc.terminal }
+
+			out := &bytes.Buffer{}
+			r := &runner{cfg: &Config{
+				AutoApprove: tc.autoApprove,
+				IOStreams:   genericclioptions.IOStreams{In: strings.NewReader(tc.answer), Out: out, ErrOut: out},
+			}}
+			plan := &planv1alpha1.Plan{Spec: planv1alpha1.PlanSpec{Packages: map[string]*planv1alpha1.PackagePlan{
+				"root": {Resources: map[string][]planv1alpha1.Resource{
+					"kubernetes_manifest.cm": {
+						{
+							Action: planv1alpha1.ActionCreate,
+							Object: map[string]any{
+								"apiVersion": "v1",
+								"kind":       "ConfigMap",
+								"metadata":   map[string]any{"name": "cm1", "namespace": "default"},
+							},
+						},
+						{
+							// unchanged resources are not printed
+							Action: planv1alpha1.ActionNoOp,
+							Object: map[string]any{
+								"apiVersion": "v1",
+								"kind":       "ConfigMap",
+								"metadata":   map[string]any{"name": "cm2", "namespace": "default"},
+							},
+						},
+					},
+				}},
+			}}}
+			differ := diff.NewDiffer(nil, nil, nil, false)
+			if err := differ.Run(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			approved, err := r.approve(plan, differ, tc.operation)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("want error, got nil")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if approved != tc.expectedApproved {
+				t.Errorf("want approved %t, got: %t", tc.expectedApproved, approved)
+			}
+			for _, expected := range tc.expectedOut {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("want output to contain %q, got: %s", expected, out.String())
+				}
+			}
+			for _, unexpected := range tc.unexpectedOut {
+				if strings.Contains(out.String(), unexpected) {
+					t.Errorf("want output w/o %q, got: %s", unexpected, out.String())
+				}
+			}
+		})
+	}
+}
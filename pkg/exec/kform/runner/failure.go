@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/henderiw/logger/log"
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// recordFailedRun records the resources that exist after a failed run in the inventory, the
+// error of the run is returned to the user, so a failure to update the inventory is only logged
+func (r *runner) recordFailedRun(ctx context.Context, actuated store.Storer[store.Storer[data.BlockData]], inventory *invv1alpha1.Inventory, pruned store.Storer[store.Storer[data.BlockData]], providers map[string]string, blockInfos map[string]map[string]*invv1alpha1.BlockInfo, inScope func(pkgName, blockName string) bool) {
+	log := log.FromContext(ctx)
+	// the resources that are not actuated and the resources to delete after
+	// the run are retained in the inventory, such that the next run converges
+	converged := getConvergedResources(actuated, inventory.GetResources(), pruned)
+	empty := true
+	converged.List(func(k store.Key, s store.Storer[data.BlockData]) {
+		s.List(func(k store.Key, bd data.BlockData) { empty = false })
+	})
+	if empty && len(inventory.Packages) == 0 {
+		// nothing got actuated and there is no inventory to update
+		return
+	}
+	statusFn := getObjectStatus(actuated, inventory, inScope, r.waiter)
+	if err := r.invManager.Apply(ctx, mergeProviders(inventory.Providers, providers), converged, blockInfos, statusFn); err != nil {
+		log.Error("cannot update inventory after failed run", "err", err.Error())
+	}
+}
+
+// getConvergedResources returns the resources to record in the inventory after a failed run:
+// the resources actuated by the run together with the resources of the inventory that were
+// neither actuated nor pruned, since these still exist. This ensures the next run converges.
+func getConvergedResources(actuated, existing, pruned store.Storer[store.Storer[data.BlockData]]) store.Storer[store.Storer[data.BlockData]] {
+	converged := memory.NewStore[store.Storer[data.BlockData]](nil)
+	known := sets.New[string]()
+	add := func(resources store.Storer[store.Storer[data.BlockData]]) {
+		resources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+			pkgName := k.Name
+			pkgStore, err := converged.Get(k)
+			if err != nil {
+				pkgStore = memory.NewStore[data.BlockData](nil)
+				converged.Create(k, pkgStore)
+			}
+			s.List(func(k store.Key, bd data.BlockData) {
+				blockData, err := pkgStore.Get(k)
+				if err != nil {
+					blockData = data.BlockData{}
+				}
+				for _, rn := range bd {
+					objectKey := getObjectKey(pkgName, rn)
+					if known.Has(objectKey) {
+						continue
+					}
+					known.Insert(objectKey)
+					blockData = blockData.Add(rn)
+				}
+				if len(blockData) != 0 {
+					pkgStore.Update(k, blockData)
+				}
+			})
+		})
+	}
+	add(compactResources(actuated))
+	// the pruned resources no longer exist, unless they got actuated again
+	compactResources(pruned).List(func(k store.Key, s store.Storer[data.BlockData]) {
+		pkgName := k.Name
+		s.List(func(k store.Key, bd data.BlockData) {
+			for _, rn := range bd {
+				known.Insert(getObjectKey(pkgName, rn))
+			}
+		})
+	})
+	add(compactResources(existing))
+	return converged
+}
+
+// compactResources returns the resources w/o the entries of the block instances that
+// did not run, a failed run leaves these entries empty
+func compactResources(resources store.Storer[store.Storer[data.BlockData]]) store.Storer[store.Storer[data.BlockData]] {
+	compacted := memory.NewStore[store.Storer[data.BlockData]](nil)
+	if resources == nil {
+		return compacted
+	}
+	resources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+		pkgStore := memory.NewStore[data.BlockData](nil)
+		s.List(func(k store.Key, bd data.BlockData) {
+			blockData := data.BlockData{}
+			for _, rn := range bd {
+				if rn != nil {
+					blockData = blockData.Add(rn)
+				}
+			}
+			if len(blockData) != 0 {
+				pkgStore.Create(k, blockData)
+			}
+		})
+		compacted.Create(k, pkgStore)
+	})
+	return compacted
+}
+
+// getObjectKey identifies an object of a package, independent of the block it belongs to
+func getObjectKey(pkgName string, rn *yaml.RNode) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", pkgName, rn.GetApiVersion(), rn.GetKind(), rn.GetNamespace(), rn.GetName())
+}
@@ -0,0 +1,232 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestGetConvergedResources(t *testing.T) {
+	newResources := func(blocks map[string][]string) store.Storer[store.Storer[data.BlockData]] {
+		resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+		pkgStore := memory.NewStore[data.BlockData](nil)
+		for blockName, names := range blocks {
+			bd := data.BlockData{}
+			for _, name := range names {
+				if name == "" {
+					// instance that did not run
+					bd = append(bd, nil)
+					continue
+				}
+				rn, err := yaml.Parse(fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: default\n", name))
+				if err != nil {
+					t.Fatalf("cannot parse resource: %s", err)
+				}
+				bd = append(bd, rn)
+			}
+			pkgStore.Create(store.ToKey(blockName), bd)
+		}
+		resources.Create(store.ToKey("root"), pkgStore)
+		return resources
+	}
+	listResources := func(resources store.Storer[store.Storer[data.BlockData]]) map[string][]string {
+		blocks := map[string][]string{}
+		resources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+			s.List(func(k store.Key, bd data.BlockData) {
+				for _, rn := range bd {
+					blocks[k.Name] = append(blocks[k.Name], rn.GetName())
+				}
+			})
+		})
+		return blocks
+	}
+
+	cases := map[string]struct {
+		actuated map[string][]string
+		existing map[string][]string
+		pruned   map[string][]string
+		expected map[string][]string
+	}{
+		"FailedInstance": {
+			// cm1 was updated, the update of cm2 failed and cm3 is new but failed
+			actuated: map[string][]string{"cm": {"cm1", "", ""}},
+			existing: map[string][]string{"cm": {"cm1", "cm2"}, "secret": {"secret1"}},
+			expected: map[string][]string{"cm": {"cm1", "cm2"}, "secret": {"secret1"}},
+		},
+		"Pruned": {
+			// cm2 got replaced, but the replacement failed
+			actuated: map[string][]string{"cm": {"cm1", ""}},
+			existing: map[string][]string{"cm": {"cm1", "cm2"}},
+			pruned:   map[string][]string{"cm": {"cm2"}},
+			expected: map[string][]string{"cm": {"cm1"}},
+		},
+		"Replaced": {
+			// cm2 got replaced
+			actuated: map[string][]string{"cm": {"cm1", "cm2"}},
+			existing: map[string][]string{"cm": {"cm1", "cm2"}},
+			pruned:   map[string][]string{"cm": {"cm2"}},
+			expected: map[string][]string{"cm": {"cm1", "cm2"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			converged := getConvergedResources(newResources(tc.actuated), newResources(tc.existing), newResources(tc.pruned))
+			if diff := cmp.Diff(tc.expected, listResources(converged)); diff != "" {
+				t.Errorf("-want resources, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeManager struct {
+	applied store.Storer[store.Storer[data.BlockData]]
+}
+
+func (r *fakeManager) GetInventory(ctx context.Context) (*invv1alpha1.Inventory, error) {
+	return nil, nil
+}
+
+func (r *fakeManager) Apply(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*invv1alpha1.BlockInfo, statusFn invv1alpha1.ObjectStatusFn) error {
+	r.applied = newActuatedResources
+	return nil
+}
+
+func (r *fakeManager) Delete(ctx context.Context) error { return nil }
+
+func (r *fakeManager) Unlock(ctx context.Context) error { return nil }
+
+func TestActuate(t *testing.T) {
+	newResources := func(blocks map[string][]string) store.Storer[store.Storer[data.BlockData]] {
+		resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+		pkgStore := memory.NewStore[data.BlockData](nil)
+		for blockName, names := range blocks {
+			bd := data.BlockData{}
+			for _, name := range names {
+				rn, err := yaml.Parse(fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: default\n", name))
+				if err != nil {
+					t.Fatalf("cannot parse resource: %s", err)
+				}
+				bd = append(bd, rn)
+			}
+			pkgStore.Create(store.ToKey(blockName), bd)
+		}
+		resources.Create(store.ToKey("root"), pkgStore)
+		return resources
+	}
+	listResources := func(resources store.Storer[store.Storer[data.BlockData]]) map[string][]string {
+		if resources == nil {
+			return nil
+		}
+		blocks := map[string][]string{}
+		resources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+			s.List(func(k store.Key, bd data.BlockData) {
+				for _, rn := range bd {
+					blocks[k.Name] = append(blocks[k.Name], rn.GetName())
+				}
+			})
+		})
+		return blocks
+	}
+	cm := func(name string) invv1alpha1.Object {
+		return invv1alpha1.Object{ObjectRef: invv1alpha1.ObjectReference{Version: "v1", Kind: "ConfigMap", Name: name, Namespace: "default"}}
+	}
+
+	cases := map[string]struct {
+		destroyFirst map[string][]string
+		destroyAfter map[string][]string
+		actuated     map[string][]string
+		// failFirst and failAfter fail the destroy after the first resource got deleted
+		failFirst       bool
+		failAfter       bool
+		expectedApplied map[string][]string
+		expectedErr     bool
+	}{
+		"Succeeded": {
+			destroyFirst: map[string][]string{"cm": {"cm1"}},
+			destroyAfter: map[string][]string{"old": {"old1", "old2"}},
+			actuated:     map[string][]string{"cm": {"cm1", "cm3"}},
+		},
+		"DestroyFirstFailed": {
+			// cm1 got deleted before the destroy failed, cm2 still exists
+			destroyFirst:    map[string][]string{"cm": {"cm1", "cm2"}},
+			actuated:        map[string][]string{"cm": {"cm1", "cm2"}},
+			failFirst:       true,
+			expectedApplied: map[string][]string{"cm": {"cm2"}, "old": {"old1", "old2"}},
+			expectedErr:     true,
+		},
+		"DestroyAfterFailed": {
+			// cm1 got replaced and cm3 created, old1 got deleted before the destroy failed
+			// and cm2 is not touched by the run
+			destroyFirst:    map[string][]string{"cm": {"cm1"}},
+			destroyAfter:    map[string][]string{"old": {"old1", "old2"}},
+			actuated:        map[string][]string{"cm": {"cm1", "cm3"}},
+			failAfter:       true,
+			expectedApplied: map[string][]string{"cm": {"cm1", "cm3", "cm2"}, "old": {"old2"}},
+			expectedErr:     true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			invManager := &fakeManager{}
+			r := &runner{cfg: &Config{}, invManager: invManager}
+			inventory := &invv1alpha1.Inventory{
+				Packages: map[string]*invv1alpha1.PackageInventory{
+					"root": {PackageResources: map[string][]invv1alpha1.Object{
+						"cm":  {cm("cm1"), cm("cm2")},
+						"old": {cm("old1"), cm("old2")},
+					}},
+				},
+			}
+			destroyFirst := newResources(tc.destroyFirst)
+			destroyAfter := newResources(tc.destroyAfter)
+			prune := func(ctx context.Context, pruneResources store.Storer[store.Storer[data.BlockData]], providers map[string]string, blockInfos map[string]map[string]*invv1alpha1.BlockInfo) (store.Storer[store.Storer[data.BlockData]], error) {
+				fail := (tc.failFirst && pruneResources == destroyFirst) || (tc.failAfter && pruneResources == destroyAfter)
+				if !fail {
+					return pruneResources, nil
+				}
+				// the first resource got deleted before the failure
+				deleted := map[string][]string{}
+				for blockName, names := range listResources(pruneResources) {
+					deleted[blockName] = names[:1]
+					break
+				}
+				return newResources(deleted), fmt.Errorf("destroy failed")
+			}
+			actuated, err := r.actuate(ctx, &actuation{
+				inventory:    inventory,
+				destroyFirst: destroyFirst,
+				destroyAfter: destroyAfter,
+				inScope:      func(pkgName, blockName string) bool { return true },
+				prune:        prune,
+				actuate: func(ctx context.Context) (store.Storer[store.Storer[data.BlockData]], error) {
+					return newResources(tc.actuated), nil
+				},
+			})
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("want error, got nil")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if diff := cmp.Diff(tc.actuated, listResources(actuated)); diff != "" {
+					t.Errorf("-want actuated, +got:\n%s", diff)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedApplied, listResources(invManager.applied)); diff != "" {
+				t.Errorf("-want inventory, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -7,11 +7,14 @@ import (
 	"os"
 
 	"github.com/henderiw/logger/log"
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
 	"github.com/kform-dev/kform/pkg/pkgio"
 	"github.com/kform-dev/kform/pkg/recorder"
 	"github.com/kform-dev/kform/pkg/recorder/diag"
 	"github.com/kform-dev/kform/pkg/syntax/parser/pkgparser"
 	"github.com/kform-dev/kform/pkg/syntax/types"
+	"github.com/kform-dev/kform/pkg/validation"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -48,11 +51,14 @@ func (r *runner) getInputVars(ctx context.Context) (map[string]any, error) {
 	for v, bd := range inputPkg.GetBlockdata(ctx) {
 		vardata, err := bd.GetVarData()
 		if err != nil {
-			errors.Join(errm, err)
+			errm = errors.Join(errm, fmt.Errorf("input %s, err: %s", v, err.Error()))
 			continue
 		}
 		inputVars[v] = vardata
 	}
+	if errm != nil {
+		return nil, errm
+	}
 	return inputVars, nil
 
 }
@@ -84,3 +90,45 @@ func (r *runner) getInputReader(_ context.Context) (pkgio.Reader[*yaml.RNode], e
 	}
 	return nil, nil
 }
+
+// validateInputs validates the input of the package against the validation of its input
+// blocks, the default of an input block is validated when no input is supplied for it
+func validateInputs(ctx context.Context, pkg *types.Package, inputVars map[string]any) error {
+	var errm error
+	for blockName, block := range types.ListBlocks(ctx, pkg.Blocks, types.ListBlockOptions{
+		Prefix: kformv1alpha1.BlockTYPE_INPUT.String(),
+	}) {
+		v, err := block.GetAttributes().GetValidation()
+		if err != nil {
+			errm = errors.Join(errm, fmt.Errorf("block %s, err: %s", blockName, err.Error()))
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		if vardata, ok := inputVars[blockName].(data.VarData); ok {
+			for idx, value := range vardata[data.DummyKey] {
+				for _, err := range validation.Validate(v, value) {
+					errm = errors.Join(errm, fmt.Errorf("invalid input %s[%d]: %s", blockName, idx, err.Error()))
+				}
+			}
+			continue
+		}
+		fileNames := block.GetFileNames()
+		for idx, rn := range block.GetData().Get() {
+			fileName := ""
+			if idx < len(fileNames) {
+				fileName = fileNames[idx]
+			}
+			value := map[string]any{}
+			if err := yaml.Unmarshal([]byte(rn.MustString()), &value); err != nil {
+				errm = errors.Join(errm, fmt.Errorf("invalid default of input %s in file %s, err: %s", blockName, fileName, err.Error()))
+				continue
+			}
+			for _, err := range validation.Validate(v, value) {
+				errm = errors.Join(errm, fmt.Errorf("invalid default of input %s in file %s: %s", blockName, fileName, err.Error()))
+			}
+		}
+	}
+	return errm
+}
@@ -0,0 +1,175 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	"github.com/kform-dev/kform/pkg/pkgio"
+	"github.com/kform-dev/kform/pkg/recorder"
+	"github.com/kform-dev/kform/pkg/recorder/diag"
+	"github.com/kform-dev/kform/pkg/syntax/parser/pkgparser"
+	"github.com/kform-dev/kform/pkg/syntax/types"
+)
+
+func TestGetBackendConfig(t *testing.T) {
+	pkgInput := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster
+  annotations:
+    kform.dev/block-type: input
+    kform.dev/default: "true"
+data:
+  host: https://10.0.0.1:6443
+`
+	backend := `apiVersion: backend.kform.dev/v1alpha1
+kind: Config
+metadata:
+  name: backend
+  annotations:
+    kform.dev/block-type: backend
+spec:
+  type: secret
+  host: input.cluster[0].data.host
+`
+	cases := map[string]struct {
+		input        string
+		expectedHost string
+	}{
+		"Default": {
+			expectedHost: "https://10.0.0.1:6443",
+		},
+		"Input": {
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster
+data:
+  host: https://10.0.0.2:6443
+`,
+			expectedHost: "https://10.0.0.2:6443",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			pkgData := memory.NewStore[[]byte](nil)
+			pkgData.Create(store.ToKey("input.yaml"), []byte(pkgInput))
+			pkgData.Create(store.ToKey("backend.yaml"), []byte(backend))
+			cfg := &Config{PackageName: "root", ResourceData: pkgData}
+			if tc.input != "" {
+				cfg.InputData = memory.NewStore[[]byte](nil)
+				cfg.InputData.Create(store.ToKey("cluster.yaml"), []byte(tc.input))
+			}
+			backendConfig, err := GetBackendConfig(ctx, cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if backendConfig.Spec.Host == nil || *backendConfig.Spec.Host != tc.expectedHost {
+				t.Errorf("want host %s, got: %v", tc.expectedHost, backendConfig.Spec.Host)
+			}
+		})
+	}
+}
+
+func TestValidateInputs(t *testing.T) {
+	pkgInput := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  annotations:
+    kform.dev/block-type: input
+    kform.dev/default: "true"
+    kform.dev/validation: |
+      rules:
+      - rule: int(self.data.replicas) < 10
+        message: replicas must be lower than 10
+data:
+  replicas: "%s"
+`
+	cases := map[string]struct {
+		defaultReplicas string
+		input           string
+		expectedError   string
+	}{
+		"ValidDefault": {
+			defaultReplicas: "3",
+		},
+		"InvalidDefault": {
+			defaultReplicas: "12",
+			expectedError:   "invalid default of input input.app in file input.yaml: replicas must be lower than 10",
+		},
+		"ValidInput": {
+			defaultReplicas: "12",
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  replicas: "3"
+`,
+		},
+		"InvalidInput": {
+			defaultReplicas: "3",
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  replicas: "12"
+`,
+			expectedError: "invalid input input.app[0]: replicas must be lower than 10",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := &Config{PackageName: "root"}
+			if tc.input != "" {
+				cfg.InputData = memory.NewStore[[]byte](nil)
+				cfg.InputData.Create(store.ToKey("app.yaml"), []byte(tc.input))
+			}
+			r := &runner{cfg: cfg}
+			inputVars, err := r.getInputVars(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			pkgRecorder := recorder.New[diag.Diagnostic]()
+			ctx = context.WithValue(ctx, types.CtxKeyRecorder, pkgRecorder)
+			ctx = context.WithValue(ctx, types.CtxKeyPackageName, cfg.PackageName)
+			ctx = context.WithValue(ctx, types.CtxKeyPackageKind, types.PackageKind_ROOT)
+			pkgData := memory.NewStore[[]byte](nil)
+			pkgData.Create(store.ToKey("input.yaml"), []byte(fmt.Sprintf(pkgInput, tc.defaultReplicas)))
+			kformDataStore, err := (&pkgio.KformMemReader{Data: pkgData}).Read(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			pkgParser, err := pkgparser.New(ctx, cfg.PackageName)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			pkg := pkgParser.Parse(ctx, kformDataStore)
+			if pkgRecorder.Get().HasError() {
+				t.Fatalf("unexpected error: %s", pkgRecorder.Get().Error())
+			}
+
+			err = validateInputs(ctx, pkg, inputVars)
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("want error %q, got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}
@@ -2,20 +2,24 @@ package runner
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 
 	"github.com/henderiw/logger/log"
 	"github.com/henderiw/store"
 	"github.com/henderiw/store/memory"
 	"github.com/kform-dev/kform-plugin/plugin"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
 	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/executor"
 	"github.com/kform-dev/kform/pkg/exec/fn/fns"
 	"github.com/kform-dev/kform/pkg/fsys"
 	"github.com/kform-dev/kform/pkg/recorder"
 	"github.com/kform-dev/kform/pkg/recorder/diag"
 	"github.com/kform-dev/kform/pkg/syntax/parser"
 	"github.com/kform-dev/kform/pkg/syntax/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 type KformConfig struct {
@@ -26,6 +30,20 @@ type KformConfig struct {
 	DryRun       bool
 	TmpDir       *fsys.Directory
 	Destroy      bool
+	// PluginDir is the directory in which the providers are installed, LockFile
+	// records the installed provider releases
+	PluginDir string
+	LockFile  string
+	// KeepGoing runs the independent blocks after a block failed
+	KeepGoing bool
+	// Filter selects the blocks of the package to run, nil runs all blocks
+	Filter *targetFilter
+	// Limiter bounds the concurrent work of the run, ProviderLimiters the
+	// concurrent requests per provider
+	Limiter          *executor.Limiter
+	ProviderLimiters map[string]*executor.Limiter
+	// Waiter waits till the applied resources are ready, nil does not wait
+	Waiter *fns.Waiter
 }
 
 func newKformContext(cfg *KformConfig) *kformContext {
@@ -44,6 +62,11 @@ type kformContext struct {
 	providerConfigs   store.Storer[string]
 	outputStore       store.Storer[data.BlockData]
 	resourcesStore    store.Storer[store.Storer[data.BlockData]]
+	// info of the resource blocks per package
+	blockInfos map[string]map[string]*invv1alpha1.BlockInfo
+	// blocks of the root package and the blocks selected by the filter
+	blocks         sets.Set[string]
+	selectedBlocks sets.Set[string]
 }
 
 func (r *kformContext) ParseAndRun(ctx context.Context, inputVars map[string]any) error {
@@ -57,6 +80,8 @@ func (r *kformContext) ParseAndRun(ctx context.Context, inputVars map[string]any
 		PackageName:  r.cfg.PkgName,
 		Path:         r.cfg.Path,
 		ResourceData: r.cfg.ResourceData,
+		PluginDir:    r.cfg.PluginDir,
+		LockFile:     r.cfg.LockFile,
 	})
 	if err != nil {
 		return err
@@ -70,6 +95,16 @@ func (r *kformContext) ParseAndRun(ctx context.Context, inputVars map[string]any
 	}
 	//kformRecorder.Print()
 
+	rootPackage, err := parser.GetRootPackage(ctx)
+	if err != nil {
+		return err
+	}
+	// the input is validated before any provider is started
+	if err := validateInputs(ctx, rootPackage, inputVars); err != nil {
+		log.Error("invalid input", "error", err)
+		return err
+	}
+
 	// initialize providers which hold the identities of the raw providers
 	// that reference the exec/initialization to startup the binaries
 	r.providers, err = parser.InitProviders(ctx)
@@ -85,10 +120,23 @@ func (r *kformContext) ParseAndRun(ctx context.Context, inputVars map[string]any
 		return err
 	}
 
-	rootPackage, err := parser.GetRootPackage(ctx)
+	blockInfos, err := getBlockInfos(ctx, rootPackage)
 	if err != nil {
 		return err
 	}
+	r.blockInfos = map[string]map[string]*invv1alpha1.BlockInfo{
+		rootPackage.Name: blockInfos,
+	}
+	r.blocks = sets.KeySet(rootPackage.DAG.GetVertices())
+	r.selectedBlocks = r.blocks
+	if r.cfg.Filter.isPartial() {
+		// a partial run only runs the selected blocks of the package
+		r.selectedBlocks = r.cfg.Filter.selectVertices(rootPackage.DAG)
+		rootPackage.DAG, err = pruneDAG(ctx, rootPackage.DAG, r.selectedBlocks)
+		if err != nil {
+			return err
+		}
+	}
 	//rootPackage.DAG.Print("root")
 
 	// run the provider DAG
@@ -128,6 +176,52 @@ func (r *kformContext) getResources() store.Storer[store.Storer[data.BlockData]]
 	return r.resourcesStore
 }
 
+func (r *kformContext) getBlockInfos() map[string]map[string]*invv1alpha1.BlockInfo {
+	return r.blockInfos
+}
+
+// getBlocks returns the names of the blocks of the root package
+func (r *kformContext) getBlocks() sets.Set[string] {
+	return r.blocks
+}
+
+// getScope returns a function that indicates if the resources of a block of the
+// inventory are in scope of the run. Blocks that are removed from the package
+// are in scope unless they are excluded or other blocks are targeted.
+func (r *kformContext) getScope() func(pkgName, blockName string) bool {
+	return func(pkgName, blockName string) bool {
+		if r.blocks.Has(blockName) {
+			return r.selectedBlocks.Has(blockName)
+		}
+		if !r.cfg.Filter.isPartial() {
+			return true
+		}
+		return !r.cfg.Filter.excludes.Has(blockName) &&
+			(r.cfg.Filter.targets.Len() == 0 || r.cfg.Filter.targets.Has(blockName))
+	}
+}
+
+// getBlockInfos returns the dependencies, lifecycle and sensitivity per resource block of the package
+func getBlockInfos(ctx context.Context, pkg *types.Package) (map[string]*invv1alpha1.BlockInfo, error) {
+	blockInfos := map[string]*invv1alpha1.BlockInfo{}
+	dependencies := pkg.GetResourceDependencies(ctx)
+	for _, block := range types.ListBlocks(ctx, pkg.Blocks) {
+		if block.GetBlockType() != kformv1alpha1.BlockTYPE_RESOURCE {
+			continue
+		}
+		lifecycle, err := block.GetAttributes().GetLifeCycle()
+		if err != nil {
+			return nil, fmt.Errorf("block %s, err: %s", block.GetBlockName(), err.Error())
+		}
+		blockInfos[block.GetBlockName()] = &invv1alpha1.BlockInfo{
+			Dependencies: dependencies[block.GetBlockName()],
+			LifeCycle:    lifecycle,
+			Sensitive:    block.GetAttributes().IsSensitive(),
+		}
+	}
+	return blockInfos, nil
+}
+
 func (r *kformContext) getProviders() map[string]string {
 	providers := map[string]string{}
 	r.providerConfigs.List(func(k store.Key, s string) {
@@ -152,6 +246,7 @@ func (r *kformContext) runProviderDAG(ctx context.Context, rootPackage *types.Pa
 		ProviderInstances: r.providerInstances,
 		Providers:         r.providers,
 		ProviderConfigs:   r.providerConfigs,
+		Limiter:           r.cfg.Limiter,
 	})
 	log.Debug("executing provider runner DAG")
 	if err := rmfn.Run(ctx, &types.VertexContext{
@@ -189,6 +284,10 @@ func (r *kformContext) runKformDAG(ctx context.Context, errCh chan error, rootPa
 		Resources:         r.resourcesStore,
 		DryRun:            r.cfg.DryRun,
 		Destroy:           r.cfg.Destroy,
+		KeepGoing:         r.cfg.KeepGoing,
+		Limiter:           r.cfg.Limiter,
+		ProviderLimiters:  r.cfg.ProviderLimiters,
+		Waiter:            r.cfg.Waiter,
 	})
 
 	log.Debug("executing package")
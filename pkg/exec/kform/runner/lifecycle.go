@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/diff"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// mergeBlockInfos returns the block infos of the inventory overwritten by the
+// block infos of the package, such that the lifecycle of the package applies to
+// the blocks that still exist and the lifecycle of the inventory to the removed blocks
+func mergeBlockInfos(invBlockInfos, blockInfos map[string]map[string]*invv1alpha1.BlockInfo) map[string]map[string]*invv1alpha1.BlockInfo {
+	merged := map[string]map[string]*invv1alpha1.BlockInfo{}
+	for _, infos := range []map[string]map[string]*invv1alpha1.BlockInfo{invBlockInfos, blockInfos} {
+		for pkgName, pkgBlockInfos := range infos {
+			if _, ok := merged[pkgName]; !ok {
+				merged[pkgName] = map[string]*invv1alpha1.BlockInfo{}
+			}
+			for blockName, blockInfo := range pkgBlockInfos {
+				merged[pkgName][blockName] = blockInfo
+			}
+		}
+	}
+	return merged
+}
+
+// checkPreventDestroy returns an error when resources of a block with the
+// preventDestroy lifecycle would be destroyed
+func checkPreventDestroy(pruneResources store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*invv1alpha1.BlockInfo) error {
+	if pruneResources == nil {
+		return nil
+	}
+	protected := []string{}
+	pruneResources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+		pkgName := k.Name
+		s.List(func(k store.Key, bd data.BlockData) {
+			if !blockInfos[pkgName][k.Name].GetLifeCycle().GetPreventDestroy() {
+				return
+			}
+			for _, rn := range bd.Get() {
+				protected = append(protected, fmt.Sprintf("%s/%s %s/%s %s", pkgName, k.Name, rn.GetKind(), rn.GetNamespace(), rn.GetName()))
+			}
+		})
+	})
+	if len(protected) != 0 {
+		sort.Strings(protected)
+		return fmt.Errorf("cannot destroy resources with lifecycle preventDestroy: %s", strings.Join(protected, ", "))
+	}
+	return nil
+}
+
+// splitReplacedResources splits the resources to prune in the resources that are replaced by a
+// new resource of the same block and need to be destroyed before the new resource is created, and
+// the remaining resources which are destroyed after the new resources are created.
+// The created blocks are identified by <package>/<block>.
+func splitReplacedResources(pruneResources store.Storer[store.Storer[data.BlockData]], createdBlocks sets.Set[string], blockInfos map[string]map[string]*invv1alpha1.BlockInfo) (store.Storer[store.Storer[data.BlockData]], store.Storer[store.Storer[data.BlockData]]) {
+	destroyFirst := memory.NewStore[store.Storer[data.BlockData]](nil)
+	destroyAfter := memory.NewStore[store.Storer[data.BlockData]](nil)
+	if pruneResources == nil {
+		return destroyFirst, destroyAfter
+	}
+	pruneResources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+		pkgName := k.Name
+		s.List(func(k store.Key, bd data.BlockData) {
+			target := destroyAfter
+			if createdBlocks.Has(fmt.Sprintf("%s/%s", pkgName, k.Name)) &&
+				!blockInfos[pkgName][k.Name].GetLifeCycle().GetCreateBeforeDestroy() {
+				target = destroyFirst
+			}
+			pkgStore, err := target.Get(store.ToKey(pkgName))
+			if err != nil {
+				pkgStore = memory.NewStore[data.BlockData](nil)
+				target.Create(store.ToKey(pkgName), pkgStore)
+			}
+			pkgStore.Create(k, bd)
+		})
+	})
+	return destroyFirst, destroyAfter
+}
+
+// getCreatedBlocks returns the blocks <package>/<block> in which the differ found resources to create
+func getCreatedBlocks(changes []*diff.Change) sets.Set[string] {
+	createdBlocks := sets.New[string]()
+	for _, change := range changes {
+		if change.Action == diff.ActionCreate {
+			createdBlocks.Insert(fmt.Sprintf("%s/%s", change.PackageName, change.BlockName))
+		}
+	}
+	return createdBlocks
+}
+
+// hasResources returns true when the store has at least one resource
+func hasResources(resources store.Storer[store.Storer[data.BlockData]]) bool {
+	found := false
+	if resources == nil {
+		return found
+	}
+	resources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+		s.List(func(k store.Key, bd data.BlockData) {
+			if bd.Len() != 0 {
+				found = true
+			}
+		})
+	})
+	return found
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/diff"
+	"github.com/kform-dev/kform/pkg/pkgio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestPrintDiffAndWriteOutputs(t *testing.T) {
+	cases := map[string]struct {
+		outputFormat string
+		expectedJSON bool
+	}{
+		"Text": {
+			outputFormat: OutputFormatText,
+		},
+		"JSON": {
+			outputFormat: OutputFormatJSON,
+			expectedJSON: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			rn, err := yaml.Parse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\ndata:\n  a: b\n")
+			if err != nil {
+				t.Fatalf("cannot parse resource: %s", err)
+			}
+			pkgStore := memory.NewStore[data.BlockData](nil)
+			pkgStore.Create(store.ToKey("kubernetes_manifest.cm"), data.BlockData{rn})
+			resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+			resources.Create(store.ToKey("root"), pkgStore)
+			outputStore := memory.NewStore[data.BlockData](nil)
+			outputStore.Create(store.ToKey("output.cm"), data.BlockData{rn.Copy()})
+
+			differ := diff.NewDiffer(nil, resources, nil, false)
+			if err := differ.Run(ctx); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			r := &runner{cfg: &Config{OutputFormat: tc.outputFormat}, outputSink: pkgio.OutputSink_StdOut}
+
+			// the outputs are written to os.Stdout
+			stdout := os.Stdout
+			pr, pw, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			os.Stdout = pw
+			printErr := r.printDiff(differ)
+			writeErr := r.writeOutputs(ctx, outputStore)
+			os.Stdout = stdout
+			pw.Close()
+			b, err := io.ReadAll(pr)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if printErr != nil || writeErr != nil {
+				t.Fatalf("unexpected error: %v, %v", printErr, writeErr)
+			}
+
+			if !tc.expectedJSON {
+				if !strings.Contains(string(b), "kind: ConfigMap") {
+					t.Errorf("want outputs on stdout, got: %s", string(b))
+				}
+				return
+			}
+			plan := &diff.JSONPlan{}
+			if err := json.Unmarshal(b, plan); err != nil {
+				t.Fatalf("want json on stdout, got err: %s, stdout: %s", err, string(b))
+			}
+			if len(plan.ResourceChanges) != 1 {
+				t.Errorf("want 1 resource change, got: %d", len(plan.ResourceChanges))
+			}
+		})
+	}
+}
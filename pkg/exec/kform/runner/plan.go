@@ -0,0 +1,281 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/henderiw/logger/log"
+	"github.com/henderiw/store"
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	planv1alpha1 "github.com/kform-dev/kform/apis/plan/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/diff"
+	"github.com/kform-dev/kform/pkg/exec/fn/fns"
+	"github.com/kform-dev/kform/pkg/inventory/config"
+	"github.com/kform-dev/kform/pkg/inventory/manager"
+	"github.com/kform-dev/kform/pkg/syntax/parser"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// buildPlan captures the rendered resources, provider configs and the inventory snapshot
+// the action of each rendered resource is taken from the changes of the differ, the
+// remaining resources in the inventory are pruned
+func (r *runner) buildPlan(
+	localInventory *unstructured.Unstructured,
+	inventory *invv1alpha1.Inventory,
+	providers map[string]string,
+	blockInfos map[string]map[string]*invv1alpha1.BlockInfo,
+	newActuatedResources store.Storer[store.Storer[data.BlockData]],
+	pruneResources store.Storer[store.Storer[data.BlockData]],
+	changes []*diff.Change,
+) (*planv1alpha1.Plan, error) {
+	plan := planv1alpha1.BuildPlan(r.cfg.PackageName, localInventory, inventory, providers)
+	plan.Spec.Path = r.cfg.Path
+	plan.Spec.Destroy = r.cfg.Destroy
+	plan.Spec.Targets = r.cfg.Targets
+	plan.Spec.Excludes = r.cfg.Excludes
+	plan.Spec.Blocks = blockInfos
+	plan.Spec.Backend = r.backendConfig.WithoutCredentials()
+
+	actions := getChangeActions(changes)
+	if err := plan.AddResources(newActuatedResources, func(pkgName, blockName string, rn *yaml.RNode) planv1alpha1.Action {
+		gvk := schema.FromAPIVersionAndKind(rn.GetApiVersion(), rn.GetKind())
+		if action, ok := actions[getChangeKey(pkgName, blockName, gvk, rn.GetNamespace(), rn.GetName())]; ok {
+			return action
+		}
+		return planv1alpha1.ActionCreate
+	}); err != nil {
+		return nil, err
+	}
+	if err := plan.AddResources(pruneResources, func(pkgName, blockName string, rn *yaml.RNode) planv1alpha1.Action {
+		return planv1alpha1.ActionDelete
+	}); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// getChangeActions returns the plan action per changed object of the differ
+func getChangeActions(changes []*diff.Change) map[string]planv1alpha1.Action {
+	actions := map[string]planv1alpha1.Action{}
+	for _, change := range changes {
+		var action planv1alpha1.Action
+		switch change.Action {
+		case diff.ActionCreate:
+			action = planv1alpha1.ActionCreate
+		case diff.ActionUpdate:
+			action = planv1alpha1.ActionUpdate
+		case diff.ActionNoOp:
+			action = planv1alpha1.ActionNoOp
+		default:
+			continue
+		}
+		actions[getChangeKey(change.PackageName, change.BlockName, change.GVK, change.NSN.Namespace, change.NSN.Name)] = action
+	}
+	return actions
+}
+
+func getChangeKey(pkgName, blockName string, gvk schema.GroupVersionKind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", pkgName, blockName, gvk.String(), namespace, name)
+}
+
+func writePlan(path string, plan *planv1alpha1.Plan) error {
+	b, err := plan.Marshal()
+	if err != nil {
+		return fmt.Errorf("cannot marshal plan, err: %s", err.Error())
+	}
+	// the plan contains the rendered resources and provider configs
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("cannot write plan %s, err: %s", path, err.Error())
+	}
+	return nil
+}
+
+func readPlan(path string) (*planv1alpha1.Plan, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read plan %s, err: %s", path, err.Error())
+	}
+	plan, err := planv1alpha1.ParsePlan(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse plan %s, err: %s", path, err.Error())
+	}
+	return plan, nil
+}
+
+// loadPlan reads the saved plan, the runner acts on the package the plan was created from
+// such that the resources are actuated with the providers installed in the package
+func (r *runner) loadPlan() (*planv1alpha1.Plan, error) {
+	plan, err := readPlan(r.cfg.PlanFile)
+	if err != nil {
+		return nil, err
+	}
+	r.cfg.PackageName = plan.Spec.PackageName
+	r.cfg.Path = plan.Spec.Path
+	return plan, nil
+}
+
+// getPlanBackendConfig renders the backend block of the package of the plan, such that the
+// credentials of the backend, which are not saved in the plan, are rendered from the input.
+// The plan is rejected when the backend differs from the backend that was planned.
+func (r *runner) getPlanBackendConfig(ctx context.Context, plan *planv1alpha1.Plan) (*backendv1alpha1.Config, error) {
+	if r.cfg.Path == "" {
+		return plan.Spec.Backend, nil
+	}
+	inputVars, err := r.getInputVars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	backendConfig, err := config.GetBackendConfig(ctx, r.cfg.Path, nil, inputVars)
+	if err != nil {
+		return nil, err
+	}
+	planned, err := json.Marshal(plan.Spec.Backend)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := json.Marshal(backendConfig.WithoutCredentials())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(planned, rendered) {
+		return nil, fmt.Errorf("the backend of package %s differs from the backend of saved plan %s, apply the plan with the input used to create it", r.cfg.PackageName, r.cfg.PlanFile)
+	}
+	return backendConfig, nil
+}
+
+// newPlanKformContext returns the kform context that actuates the planned resources, the
+// providers are selected from the plugin dir of the package and verified against its lock file
+func (r *runner) newPlanKformContext(plan *planv1alpha1.Plan, resources store.Storer[store.Storer[data.BlockData]]) (*kformContext, error) {
+	invResources := getInventoryResources(resources, plan.Spec.Providers, plan.Spec.Blocks)
+	// the kform file provides the source and version of the providers
+	if err := addKformFile(r.cfg.Path, invResources); err != nil {
+		return nil, err
+	}
+	return newKformContext(&KformConfig{
+		Kind:             fns.DagRunInventory,
+		PkgName:          r.cfg.PackageName,
+		ResourceData:     invResources,
+		PluginDir:        parser.GetPluginDir(r.cfg.Path),
+		LockFile:         parser.GetLockFile(r.cfg.Path),
+		KeepGoing:        r.cfg.KeepGoing,
+		Limiter:          r.limiter,
+		ProviderLimiters: r.providerLimiters,
+		Waiter:           r.waiter,
+	}), nil
+}
+
+// actuatePlan actuates the planned resources as they were rendered when the plan was created,
+// the actuated resources are returned, also when the actuation fails
+func (r *runner) actuatePlan(ctx context.Context, plan *planv1alpha1.Plan, resources store.Storer[store.Storer[data.BlockData]]) (store.Storer[store.Storer[data.BlockData]], error) {
+	log := log.FromContext(ctx)
+	kformCtx, err := r.newPlanKformContext(plan, resources)
+	if err != nil {
+		return nil, err
+	}
+	if err := kformCtx.ParseAndRun(ctx, map[string]any{}); err != nil {
+		log.Error("plan parseAndRun failed", "err", err.Error())
+		return kformCtx.getResources(), err
+	}
+	return kformCtx.getResources(), nil
+}
+
+// runPlan actuates the resources of a saved plan w/o re-rendering the package
+// the plan is rejected when the inventory changed since the plan was created
+func (r *runner) runPlan(ctx context.Context) error {
+	log := log.FromContext(ctx)
+	log.Debug("run plan", "plan", r.cfg.PlanFile)
+
+	plan, err := r.loadPlan()
+	if err != nil {
+		return err
+	}
+
+	r.backendConfig, err = r.getPlanBackendConfig(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	r.invManager, err = manager.New(ctx, plan.GetInventoryInfo(), r.cfg.Factory, r.backendConfig, invv1alpha1.ActuationStrategyApply, true)
+	if err != nil {
+		return err
+	}
+	defer r.unlockInventory(ctx)
+	inventory, err := r.invManager.GetInventory(ctx)
+	if err != nil {
+		return err
+	}
+	stale, err := plan.IsStale(inventory)
+	if err != nil {
+		return err
+	}
+	if stale {
+		return fmt.Errorf("saved plan %s is stale, the inventory changed since the plan was created; run kform plan again", r.cfg.PlanFile)
+	}
+	// inScope indicates if the resources of a block of the inventory are part of the plan,
+	// the resources of the blocks that are not part of a partial plan are retained as is
+	inScope := func(pkgName, blockName string) bool { return true }
+	if plan.IsPartial() {
+		newTargetFilter(plan.Spec.Targets, plan.Spec.Excludes).warn(r.errOut())
+		plannedBlocks := plan.GetBlocks(planv1alpha1.ActionCreate, planv1alpha1.ActionUpdate, planv1alpha1.ActionNoOp, planv1alpha1.ActionDelete)
+		inScope = func(pkgName, blockName string) bool {
+			return plannedBlocks.Has(fmt.Sprintf("%s/%s", pkgName, blockName))
+		}
+	}
+
+	pruneResources, err := plan.GetResources(planv1alpha1.ActionDelete)
+	if err != nil {
+		return err
+	}
+	blockInfos := mergeBlockInfos(plan.Spec.Inventory.GetBlockInfos(), plan.Spec.Blocks)
+	if err := checkPreventDestroy(pruneResources, blockInfos); err != nil {
+		return err
+	}
+	// replaced resources are destroyed before the new resources are created,
+	// unless the lifecycle of the block requests createBeforeDestroy
+	destroyFirst, destroyAfter := splitReplacedResources(pruneResources, plan.GetBlocks(planv1alpha1.ActionCreate), blockInfos)
+	a := &actuation{
+		inventory:    inventory,
+		destroyFirst: destroyFirst,
+		destroyAfter: destroyAfter,
+		invProviders: plan.Spec.Inventory.Providers,
+		providers:    plan.Spec.Providers,
+		blockInfos:   blockInfos,
+		inScope:      inScope,
+		prune:        r.prune,
+	}
+	if !plan.Spec.Destroy {
+		// the unchanged resources are actuated as well, such that the inventory
+		// records all resources of the package
+		resources, err := plan.GetResources(planv1alpha1.ActionCreate, planv1alpha1.ActionUpdate, planv1alpha1.ActionNoOp)
+		if err != nil {
+			return err
+		}
+		a.actuate = func(ctx context.Context) (store.Storer[store.Storer[data.BlockData]], error) {
+			return r.actuatePlan(ctx, plan, resources)
+		}
+	}
+	newActuatedResources, err := r.actuate(ctx, a)
+	if err != nil {
+		return err
+	}
+
+	providers := plan.Spec.Providers
+	statusFn := getObjectStatus(newActuatedResources, inventory, inScope, r.waiter)
+	if plan.IsPartial() {
+		untouchedResources := filterResources(inventory.GetResources(), func(pkgName, blockName string) bool {
+			return !inScope(pkgName, blockName)
+		})
+		newActuatedResources = mergeResources(newActuatedResources, untouchedResources)
+		providers = mergeProviders(inventory.Providers, providers)
+	} else if plan.Spec.Destroy {
+		return r.invManager.Delete(ctx)
+	}
+	return r.invManager.Apply(ctx, providers, newActuatedResources, blockInfos, statusFn)
+}
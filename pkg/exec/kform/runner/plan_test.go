@@ -0,0 +1,304 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	lockv1alpha1 "github.com/kform-dev/kform/apis/lock/v1alpha1"
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	planv1alpha1 "github.com/kform-dev/kform/apis/plan/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/diff"
+	"github.com/kform-dev/kform/pkg/inventory/config"
+	"github.com/kform-dev/kform/pkg/recorder"
+	"github.com/kform-dev/kform/pkg/recorder/diag"
+	"github.com/kform-dev/kform/pkg/syntax/address"
+	"github.com/kform-dev/kform/pkg/syntax/parser"
+	"github.com/kform-dev/kform/pkg/syntax/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestPlanInstalledProvider(t *testing.T) {
+	source := "europe-docker.pkg.dev/srlinux/eu.gcr.io"
+	kformFile := fmt.Sprintf(`apiVersion: meta.pkg.kform.dev/v1alpha1
+kind: KformFile
+metadata:
+  name: test
+spec:
+  providerRequirements:
+    kubernetes:
+      source: %s
+      version: ">= 0.0.1, < 0.1.0"
+`, source)
+	providerConfig := `apiVersion: kubernetes.provider.kform.dev/v1alpha1
+kind: ProviderConfig
+metadata:
+  name: kubernetes
+  annotations:
+    kform.dev/block-type: provider
+spec:
+  configPath: ~/.kube/config
+`
+
+	ctx := context.Background()
+	// the package has the kubernetes provider installed and locked
+	path := t.TempDir()
+	if err := os.WriteFile(filepath.Join(path, kformv1alpha1.KformFileName), []byte(kformFile), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pkg, err := address.GetPackage(store.ToKey("kubernetes"), source)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	execPath := filepath.Join(parser.GetPluginDir(path), pkg.ExecPathWithVersion("0.0.1"))
+	if err := os.MkdirAll(filepath.Dir(execPath), 0755); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := os.WriteFile(execPath, []byte("binary"), 0755); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lock := lockv1alpha1.BuildLock()
+	lock.Spec.Providers["kubernetes"] = &lockv1alpha1.ProviderLock{
+		Source:  source,
+		Version: "0.0.1",
+		Platforms: map[string]*lockv1alpha1.PlatformChecksums{
+			pkg.Platform.String(): {Manifest: "sha256:0", Binary: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("binary")))},
+		},
+	}
+	b, err := lock.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := os.WriteFile(parser.GetLockFile(path), b, 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rn, err := yaml.Parse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\n  annotations: {}\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pkgStore := memory.NewStore[data.BlockData](nil)
+	pkgStore.Create(store.ToKey("kubernetes_manifest.cm"), data.BlockData{rn})
+	resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+	resources.Create(store.ToKey("test"), pkgStore)
+
+	r := &runner{cfg: &Config{PackageName: "test", Path: path}}
+	plan, err := r.buildPlan(config.GetFakeInventoryInfo("test"), nil, map[string]string{"kubernetes": providerConfig}, nil, resources, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	planFile := filepath.Join(t.TempDir(), "plan.yaml")
+	if err := writePlan(planFile, plan); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the saved plan is applied with the provider installed in the package
+	r = &runner{cfg: &Config{PlanFile: planFile}}
+	plan, err = r.loadPlan()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	plannedResources, err := plan.GetResources(planv1alpha1.ActionCreate)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	kformCtx, err := r.newPlanKformContext(plan, plannedResources)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rec := recorder.New[diag.Diagnostic]()
+	ctx = context.WithValue(ctx, types.CtxKeyRecorder, rec)
+	p, err := parser.NewKformParser(ctx, &parser.Config{
+		PackageName:  kformCtx.cfg.PkgName,
+		Path:         kformCtx.cfg.Path,
+		ResourceData: kformCtx.cfg.ResourceData,
+		PluginDir:    kformCtx.cfg.PluginDir,
+		LockFile:     kformCtx.cfg.LockFile,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	p.Parse(ctx)
+	if rec.Get().HasError() {
+		t.Fatalf("unexpected error: %s", rec.Get().Error())
+	}
+	// the stub binary of the provider cannot be started, which shows the provider
+	// was located in the plugin dir of the package
+	if _, err := p.InitProviders(ctx); err == nil || !strings.Contains(err.Error(), execPath) {
+		t.Errorf("want provider %s to be started, got: %v", execPath, err)
+	}
+}
+
+func TestPlanBackendConfig(t *testing.T) {
+	pkgInput := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster
+  annotations:
+    kform.dev/block-type: input
+    kform.dev/default: "true"
+data:
+  host: https://10.0.0.1:6443
+  token: default-token
+`
+	backend := `apiVersion: backend.kform.dev/v1alpha1
+kind: Config
+metadata:
+  name: backend
+  annotations:
+    kform.dev/block-type: backend
+spec:
+  type: secret
+  host: input.cluster[0].data.host
+  token: input.cluster[0].data.token
+`
+	input := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster
+data:
+  host: %s
+  token: secret-token
+`
+	cases := map[string]struct {
+		host        string
+		expectedErr bool
+	}{
+		"SameBackend": {
+			host: "https://10.0.0.2:6443",
+		},
+		"OtherBackend": {
+			host:        "https://10.0.0.3:6443",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			path := t.TempDir()
+			if err := os.WriteFile(filepath.Join(path, "input.yaml"), []byte(pkgInput), 0644); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err := os.WriteFile(filepath.Join(path, "backend.yaml"), []byte(backend), 0644); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			planInput := filepath.Join(t.TempDir(), "input.yaml")
+			if err := os.WriteFile(planInput, []byte(fmt.Sprintf(input, "https://10.0.0.2:6443")), 0644); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			applyInput := filepath.Join(t.TempDir(), "input.yaml")
+			if err := os.WriteFile(applyInput, []byte(fmt.Sprintf(input, tc.host)), 0644); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			r := &runner{cfg: &Config{PackageName: "test", Path: path, Input: planInput}}
+			backendConfig, err := GetBackendConfig(ctx, r.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			r.backendConfig = backendConfig
+			plan, err := r.buildPlan(config.GetFakeInventoryInfo("test"), nil, nil, nil, memory.NewStore[store.Storer[data.BlockData]](nil), nil, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			planFile := filepath.Join(t.TempDir(), "plan.yaml")
+			if err := writePlan(planFile, plan); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			// the saved plan is only readable by the owner and has no credentials
+			info, err := os.Stat(planFile)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("want plan mode %s, got: %s", os.FileMode(0600), info.Mode().Perm())
+			}
+			b, err := os.ReadFile(planFile)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if strings.Contains(string(b), "secret-token") {
+				t.Errorf("want plan w/o backend credentials, got:\n%s", string(b))
+			}
+
+			// the credentials are rendered from the input when the plan is applied
+			r = &runner{cfg: &Config{PlanFile: planFile, Input: applyInput}}
+			plan, err = r.loadPlan()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			backendConfig, err = r.getPlanBackendConfig(ctx, plan)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if backendConfig.Spec.Token == nil || *backendConfig.Spec.Token != "secret-token" {
+				t.Errorf("want token secret-token, got: %v", backendConfig.Spec.Token)
+			}
+		})
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	newResources := func(cms map[string]string) store.Storer[store.Storer[data.BlockData]] {
+		bd := data.BlockData{}
+		for _, name := range []string{"cm1", "cm2", "cm3", "cm4"} {
+			value, ok := cms[name]
+			if !ok {
+				continue
+			}
+			rn, err := yaml.Parse(fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: default\ndata:\n  a: %s\n", name, value))
+			if err != nil {
+				t.Fatalf("cannot parse resource: %s", err)
+			}
+			bd = append(bd, rn)
+		}
+		pkgStore := memory.NewStore[data.BlockData](nil)
+		pkgStore.Create(store.ToKey("kubernetes_manifest.cm"), bd)
+		resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+		resources.Create(store.ToKey("root"), pkgStore)
+		return resources
+	}
+
+	ctx := context.Background()
+	// cm1 is unchanged, cm2 is updated, cm3 is created and cm4 is pruned
+	existing := newResources(map[string]string{"cm1": "a", "cm2": "a", "cm4": "a"})
+	resources := newResources(map[string]string{"cm1": "a", "cm2": "b", "cm3": "a"})
+	differ := diff.NewDiffer(existing, resources, nil, false)
+	if err := differ.Run(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r := &runner{cfg: &Config{PackageName: "root"}}
+	plan, err := r.buildPlan(config.GetFakeInventoryInfo("root"), nil, nil, nil, resources, differ.GetResourceToPrune(), differ.GetChanges())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	actions := map[string]planv1alpha1.Action{}
+	for _, resource := range plan.Spec.Packages["root"].Resources["kubernetes_manifest.cm"] {
+		actions[resourceRef(resource.Object)] = resource.Action
+	}
+	expected := map[string]planv1alpha1.Action{
+		"v1/ConfigMap default/cm1": planv1alpha1.ActionNoOp,
+		"v1/ConfigMap default/cm2": planv1alpha1.ActionUpdate,
+		"v1/ConfigMap default/cm3": planv1alpha1.ActionCreate,
+		"v1/ConfigMap default/cm4": planv1alpha1.ActionDelete,
+	}
+	if diff := cmp.Diff(expected, actions); diff != "" {
+		t.Errorf("-want actions, +got:\n%s", diff)
+	}
+}
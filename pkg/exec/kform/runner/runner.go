@@ -3,22 +3,31 @@ package runner
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/henderiw/logger/log"
 	"github.com/henderiw/store"
 	"github.com/henderiw/store/memory"
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
 	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
 	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	planv1alpha1 "github.com/kform-dev/kform/apis/plan/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
 	"github.com/kform-dev/kform/pkg/exec/diff"
+	"github.com/kform-dev/kform/pkg/exec/executor"
 	"github.com/kform-dev/kform/pkg/exec/fn/fns"
+	"github.com/kform-dev/kform/pkg/fsys"
 	"github.com/kform-dev/kform/pkg/inventory/config"
 	"github.com/kform-dev/kform/pkg/inventory/manager"
 	"github.com/kform-dev/kform/pkg/pkgio"
+	"github.com/kform-dev/kform/pkg/syntax/parser"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
 
@@ -28,6 +37,7 @@ type Runner interface {
 
 type Config struct {
 	Factory      util.Factory
+	IOStreams    genericclioptions.IOStreams
 	PackageName  string
 	Input        string // used for none, file or dir
 	InputData    store.Storer[[]byte]
@@ -39,24 +49,80 @@ type Config struct {
 	Destroy      bool
 	AutoApprove  bool
 	InventoryID  string
+	PlanOut      string // path where the plan is saved when planning
+	OutputFormat string // format in which the plan is printed: text or json
+	PlanFile     string // path of a saved plan that is applied w/o re-rendering
+	// ShowSensitive reveals the values of sensitive blocks in the plan and the output
+	ShowSensitive bool
+	// Targets and Excludes select the blocks <RESOURCE_TYPE>.<RESOURCE_ID> of a partial run
+	Targets  []string
+	Excludes []string
+	// Parallelism bounds the blocks and block instances that run concurrently, 0 is unlimited
+	Parallelism int
+	// ProviderParallelism bounds the concurrent requests per provider
+	ProviderParallelism map[string]int
+	// KeepGoing runs the independent blocks after a block failed and records the
+	// actuated resources in the inventory, such that the next run converges
+	KeepGoing bool
+	// Wait waits till the applied resources are ready, up to Timeout per resource, and
+	// records their reconcile status in the inventory. The dependent blocks wait as well.
+	Wait    bool
+	Timeout time.Duration
 }
 
+const (
+	OutputFormatText = "text"
+	OutputFormatJSON = "json"
+)
+
 func NewKformRunner(cfg *Config) Runner {
+	providerLimiters := make(map[string]*executor.Limiter, len(cfg.ProviderParallelism))
+	for provider, n := range cfg.ProviderParallelism {
+		providerLimiters[provider] = executor.NewLimiter(n)
+	}
+	var waiter *fns.Waiter
+	if cfg.Wait && !cfg.Destroy {
+		waiter = fns.NewWaiter(cfg.Timeout)
+	}
 	return &runner{
-		cfg: cfg,
+		cfg:              cfg,
+		filter:           newTargetFilter(cfg.Targets, cfg.Excludes),
+		limiter:          executor.NewLimiter(cfg.Parallelism),
+		providerLimiters: providerLimiters,
+		waiter:           waiter,
 	}
 }
 
+// GetBackendConfig returns the config of the backend block of the package at cfg.Path, the
+// input of cfg is used to render the expressions of the backend block
+func GetBackendConfig(ctx context.Context, cfg *Config) (*backendv1alpha1.Config, error) {
+	r := &runner{cfg: cfg}
+	inputVars, err := r.getInputVars(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return config.GetBackendConfig(ctx, cfg.Path, cfg.ResourceData, inputVars)
+}
+
 type runner struct {
-	cfg        *Config
-	outputSink pkgio.OutputSink
-	invManager manager.Manager
+	cfg              *Config
+	filter           *targetFilter
+	limiter          *executor.Limiter
+	providerLimiters map[string]*executor.Limiter
+	waiter           *fns.Waiter
+	outputSink       pkgio.OutputSink
+	backendConfig    *backendv1alpha1.Config
+	invManager       manager.Manager
 }
 
 func (r *runner) Run(ctx context.Context) error {
 	log := log.FromContext(ctx)
 	log.Debug("run")
 
+	if r.cfg.PlanFile != "" {
+		return r.runPlan(ctx)
+	}
+
 	var err error
 	// get the local inventory file, which serves as a reference to lookup
 	// the inventory in the cluster backend when it was not supplied
@@ -70,11 +136,26 @@ func (r *runner) Run(ctx context.Context) error {
 		}
 	}
 
-	r.invManager, err = manager.New(ctx, localInventory, r.cfg.Factory, invv1alpha1.ActuationStrategyApply)
+	// the input is collected upfront since the backend block can refer to it
+	inputVars, err := r.getInputVars(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the backend block of the package selects where the inventory is stored
+	r.backendConfig, err = config.GetBackendConfig(ctx, r.cfg.Path, r.cfg.ResourceData, inputVars)
 	if err != nil {
 		return err
 	}
 
+	// the inventory is locked for the duration of the run, such that concurrent
+	// runs on the same inventory do not overwrite each others inventory
+	r.invManager, err = manager.New(ctx, localInventory, r.cfg.Factory, r.backendConfig, invv1alpha1.ActuationStrategyApply, true)
+	if err != nil {
+		return err
+	}
+	defer r.unlockInventory(ctx)
+
 	inventory, err := r.invManager.GetInventory(ctx)
 	if err != nil {
 		return err
@@ -84,13 +165,20 @@ func (r *runner) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if err := addKformFile(r.cfg.Path, invResources); err != nil {
+		return err
+	}
 
 	invkformCtx := newKformContext(&KformConfig{
 		Kind:    fns.DagRunInventory,
 		PkgName: r.cfg.PackageName,
 		//Path:         r.cfg.Path,
-		ResourceData: invResources, // path is not needed as invResources take care of the data
-		DryRun:       r.cfg.DryRun,
+		ResourceData:     invResources, // path is not needed as invResources take care of the data
+		PluginDir:        parser.GetPluginDir(r.cfg.Path),
+		LockFile:         parser.GetLockFile(r.cfg.Path),
+		DryRun:           r.cfg.DryRun,
+		Limiter:          r.limiter,
+		ProviderLimiters: r.providerLimiters,
 	})
 	if err := invkformCtx.ParseAndRun(ctx, map[string]any{}); err != nil {
 		log.Error("inventory parseAndRun failed", "err", err.Error())
@@ -104,105 +192,296 @@ func (r *runner) Run(ctx context.Context) error {
 	var newActuatedResources store.Storer[store.Storer[data.BlockData]]
 	var outputStore store.Storer[data.BlockData]
 	var kformProviders map[string]string
-	// when this is not a detroy run we collect inputVars and run the kform dag
+	var kformBlockInfos map[string]map[string]*invv1alpha1.BlockInfo
+	// inScope indicates if the resources of a block of the inventory are part of the run
+	inScope := r.filter.getInventoryScope(inventory.GetBlockInfos())
+	// when this is not a detroy run we run the kform dag
 	if !r.cfg.Destroy {
-		inputVars, err := r.getInputVars(ctx)
-		if err != nil {
-			return err
-		}
-
 		r.outputSink, err = r.getOuputSink(ctx)
 		if err != nil {
 			return err
 		}
 
-		kformCtx := newKformContext(&KformConfig{
-			Kind:         fns.DagRunRegular,
-			PkgName:      r.cfg.PackageName,
-			Path:         r.cfg.Path,
-			ResourceData: r.cfg.ResourceData, // required for processor runner
-			DryRun:       r.cfg.DryRun,
-		})
-		if err := kformCtx.ParseAndRun(ctx, inputVars); err != nil {
-			log.Error("regular parseAndRun failed", "err", err.Error())
+		// the package is rendered in dryRun, such that nothing gets actuated
+		// before the plan is approved; the approved plan is actuated as rendered
+		kformCtx, err := r.renderPackage(ctx, inputVars)
+		if err != nil {
 			return err
 		}
 		outputStore = kformCtx.getOutputStore()
 		kformProviders = kformCtx.getProviders()
+		kformBlockInfos = kformCtx.getBlockInfos()
 		newActuatedResources = kformCtx.getResources()
+		inScope = kformCtx.getScope()
+		if err := r.filter.validateTargets(kformCtx.getBlocks().Union(listBlocks(inventory.GetBlockInfos()))); err != nil {
+			return err
+		}
+	} else if err := r.filter.validateTargets(listBlocks(inventory.GetBlockInfos())); err != nil {
+		return err
+	}
+
+	// a partial run only acts on the resources in scope, the resources of the other
+	// blocks are retained in the inventory as is
+	var untouchedResources store.Storer[store.Storer[data.BlockData]]
+	if r.filter.isPartial() {
+		r.filter.warn(r.errOut())
+		existingActuatedResources = filterResources(existingActuatedResources, inScope)
+		untouchedResources = filterResources(inventory.GetResources(), func(pkgName, blockName string) bool {
+			return !inScope(pkgName, blockName)
+		})
 	}
 
 	//listPackageResources(ctx, "new", newActuatedResources)
 
+	// the lifecycle and sensitivity of the package applies to the existing blocks,
+	// the removed blocks retain the lifecycle and sensitivity of the inventory
+	blockInfos := mergeBlockInfos(inventory.GetBlockInfos(), kformBlockInfos)
+
 	// we prepare the differ to diff both resources we collect
 	// if is possible we get nil stores but the differ is able to handle this
-	differ, err := diff.NewDiffer(existingActuatedResources, newActuatedResources)
-	if err != nil {
+	differ := diff.NewDiffer(existingActuatedResources, newActuatedResources, blockInfos, r.cfg.ShowSensitive)
+	if err := differ.Run(ctx); err != nil {
 		return err
 	}
-	defer differ.TearDown() // delete the directories when done
-	if err := differ.Run(ctx); err != nil {
+	if err := checkPreventDestroy(differ.GetResourceToPrune(), blockInfos); err != nil {
 		return err
 	}
 
 	if r.cfg.DryRun {
-		if err := diffExec(differ.FromPath(), differ.ToPath()); err != nil {
+		if err := r.printDiff(differ); err != nil {
 			return err
 		}
+		if r.cfg.PlanOut != "" {
+			plan, err := r.buildPlan(localInventory, inventory, kformProviders, kformBlockInfos, newActuatedResources, differ.GetResourceToPrune(), differ.GetChanges())
+			if err != nil {
+				return err
+			}
+			if err := writePlan(r.cfg.PlanOut, plan); err != nil {
+				return err
+			}
+		}
 	} else {
-		//
-		// delete the remaining resources
-		listPackageResources("inv to be deleted", differ.GetResourceToPrune())
-		// get inventory resource to destroy
-		invResources := getInventoryResourcesToDelete(differ.GetResourceToPrune(), invProviders)
-		// invoke the kform context to destroy the resources
-		invkformCtx := newKformContext(&KformConfig{
-			Kind:         fns.DagRunInventory,
-			PkgName:      r.cfg.PackageName,
-			Path:         r.cfg.Path,
-			ResourceData: invResources,
-			DryRun:       r.cfg.DryRun,
-			//TmpDir:       invDir, // directory where tmp inventory information is stored
-			Destroy: true,
-		})
-		if err := invkformCtx.ParseAndRun(ctx, map[string]any{}); err != nil {
+		plan, err := r.buildPlan(localInventory, inventory, kformProviders, kformBlockInfos, newActuatedResources, differ.GetResourceToPrune(), differ.GetChanges())
+		if err != nil {
 			return err
 		}
-
-		// when we detroy we delete the inventory
+		operation := "Apply"
 		if r.cfg.Destroy {
+			operation = "Destroy"
+		}
+		approved, err := r.approve(plan, differ, operation)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			return nil
+		}
+		// replaced resources are destroyed before the new resources are created,
+		// unless the lifecycle of the block requests createBeforeDestroy
+		destroyFirst, destroyAfter := splitReplacedResources(differ.GetResourceToPrune(), getCreatedBlocks(differ.GetChanges()), blockInfos)
+		a := &actuation{
+			inventory:    inventory,
+			destroyFirst: destroyFirst,
+			destroyAfter: destroyAfter,
+			invProviders: invProviders,
+			providers:    kformProviders,
+			blockInfos:   blockInfos,
+			inScope:      inScope,
+			prune:        r.prune,
+		}
+		if !r.cfg.Destroy {
+			// actuate the approved resources, the package is not rendered again
+			// such that exactly what was approved gets actuated
+			resources, err := plan.GetResources(planv1alpha1.ActionCreate, planv1alpha1.ActionUpdate, planv1alpha1.ActionNoOp)
+			if err != nil {
+				return err
+			}
+			a.actuate = func(ctx context.Context) (store.Storer[store.Storer[data.BlockData]], error) {
+				return r.actuatePlan(ctx, plan, resources)
+			}
+		}
+		newActuatedResources, err = r.actuate(ctx, a)
+		if err != nil {
+			return err
+		}
+
+		statusFn := getObjectStatus(newActuatedResources, inventory, inScope, r.waiter)
+		// when we detroy we delete the inventory, unless the run is partial
+		if r.filter.isPartial() {
+			newActuatedResources = mergeResources(newActuatedResources, untouchedResources)
+			kformProviders = mergeProviders(inventory.Providers, kformProviders)
+		} else if r.cfg.Destroy {
 			return r.invManager.Delete(ctx)
 		}
-		if err := r.invManager.Apply(ctx, kformProviders, newActuatedResources); err != nil {
+		if err := r.invManager.Apply(ctx, kformProviders, newActuatedResources, blockInfos, statusFn); err != nil {
 			return err
 		}
 	}
 
+	return r.writeOutputs(ctx, outputStore)
+}
+
+// printDiff prints the changes of the plan in the output format
+func (r *runner) printDiff(differ *diff.Differ) error {
+	switch r.cfg.OutputFormat {
+	case OutputFormatJSON:
+		return differ.PrintJSON(r.out())
+	default:
+		if err := differ.Print(r.out()); err != nil {
+			return err
+		}
+		differ.PrintSummary(r.out())
+	}
+	return nil
+}
+
+// writeOutputs writes the outputs of the package to the output sink. In json format stdout
+// holds the json plan, such that the outputs are only written when --out is supplied.
+func (r *runner) writeOutputs(ctx context.Context, outputStore store.Storer[data.BlockData]) error {
+	if r.cfg.OutputFormat == OutputFormatJSON && r.outputSink == pkgio.OutputSink_StdOut {
+		return nil
+	}
 	w := pkgio.KformWriter{
-		Type:      r.outputSink,
-		Path:      r.cfg.Output,
-		OuputData: r.cfg.OutputData,
+		Type:          r.outputSink,
+		Path:          r.cfg.Output,
+		OuputData:     r.cfg.OutputData,
+		ShowSensitive: r.cfg.ShowSensitive,
 	}
 	return w.Write(ctx, outputStore)
 }
 
-func listPackageResources(prefix string, pkgResourcesStore store.Storer[store.Storer[data.BlockData]]) {
+// unlockInventory releases the lock of the inventory, also when the run got cancelled
+func (r *runner) unlockInventory(ctx context.Context) {
+	log := log.FromContext(ctx)
+	if err := r.invManager.Unlock(context.WithoutCancel(ctx)); err != nil {
+		log.Error("cannot unlock inventory", "err", err.Error())
+	}
+}
+
+// renderPackage parses and runs the kform package in dryRun, the providers
+// do not actuate the resources
+func (r *runner) renderPackage(ctx context.Context, inputVars map[string]any) (*kformContext, error) {
+	log := log.FromContext(ctx)
+	kformCtx := newKformContext(&KformConfig{
+		Kind:             fns.DagRunRegular,
+		PkgName:          r.cfg.PackageName,
+		Path:             r.cfg.Path,
+		ResourceData:     r.cfg.ResourceData, // required for processor runner
+		PluginDir:        parser.GetPluginDir(r.cfg.Path),
+		LockFile:         parser.GetLockFile(r.cfg.Path),
+		DryRun:           true,
+		KeepGoing:        r.cfg.KeepGoing,
+		Filter:           r.filter,
+		Limiter:          r.limiter,
+		ProviderLimiters: r.providerLimiters,
+		Waiter:           r.waiter,
+	})
+	if err := kformCtx.ParseAndRun(ctx, inputVars); err != nil {
+		log.Error("regular parseAndRun failed", "err", err.Error())
+		return kformCtx, err
+	}
+	return kformCtx, nil
+}
+
+// pruneFn destroys the resources and returns the resources that got deleted, also when it fails
+type pruneFn func(ctx context.Context, pruneResources store.Storer[store.Storer[data.BlockData]], providers map[string]string, blockInfos map[string]map[string]*invv1alpha1.BlockInfo) (store.Storer[store.Storer[data.BlockData]], error)
+
+// actuation describes how a run changes the resources of the inventory
+type actuation struct {
+	inventory *invv1alpha1.Inventory
+	// destroyFirst are destroyed before and destroyAfter after the resources are actuated
+	destroyFirst store.Storer[store.Storer[data.BlockData]]
+	destroyAfter store.Storer[store.Storer[data.BlockData]]
+	// invProviders are the provider configs to destroy the resources of the inventory,
+	// providers are the provider configs of the run
+	invProviders map[string]string
+	providers    map[string]string
+	blockInfos   map[string]map[string]*invv1alpha1.BlockInfo
+	inScope      func(pkgName, blockName string) bool
+	prune        pruneFn
+	// actuate actuates the resources of the run and returns the actuated resources, also
+	// when it fails; nil for a destroy run
+	actuate func(ctx context.Context) (store.Storer[store.Storer[data.BlockData]], error)
+}
+
+// actuate destroys the destroyFirst resources, actuates the resources of the run and destroys the
+// destroyAfter resources. When a step fails the resources that exist after the failure are recorded
+// in the inventory, such that they are not orphaned and the next run converges.
+func (r *runner) actuate(ctx context.Context, a *actuation) (store.Storer[store.Storer[data.BlockData]], error) {
+	deleted, err := a.prune(ctx, a.destroyFirst, a.invProviders, a.blockInfos)
+	if err != nil {
+		r.recordFailedRun(ctx, nil, a.inventory, deleted, a.providers, a.blockInfos, a.inScope)
+		return nil, err
+	}
+	var actuated store.Storer[store.Storer[data.BlockData]]
+	if a.actuate != nil {
+		actuated, err = a.actuate(ctx)
+		if err != nil {
+			r.recordFailedRun(ctx, actuated, a.inventory, a.destroyFirst, a.providers, a.blockInfos, a.inScope)
+			return nil, err
+		}
+	}
+	deleted, err = a.prune(ctx, a.destroyAfter, a.invProviders, a.blockInfos)
+	if err != nil {
+		r.recordFailedRun(ctx, actuated, a.inventory, mergeResources(a.destroyFirst, deleted), a.providers, a.blockInfos, a.inScope)
+		return nil, err
+	}
+	return actuated, nil
+}
+
+// prune destroys the resources from the inventory, the dependencies of the
+// blocks ensure the resources get deleted in reverse order. The deleted
+// resources are returned, also when the destroy fails.
+func (r *runner) prune(ctx context.Context, pruneResources store.Storer[store.Storer[data.BlockData]], providers map[string]string, blockInfos map[string]map[string]*invv1alpha1.BlockInfo) (store.Storer[store.Storer[data.BlockData]], error) {
+	if !hasResources(pruneResources) {
+		return nil, nil
+	}
+	listPackageResources(ctx, "inv to be deleted", pruneResources)
+	invResources := getInventoryResources(pruneResources, providers, blockInfos)
+	if err := addKformFile(r.cfg.Path, invResources); err != nil {
+		return nil, err
+	}
+	// invoke the kform context to destroy the resources
+	invkformCtx := newKformContext(&KformConfig{
+		Kind:             fns.DagRunInventory,
+		PkgName:          r.cfg.PackageName,
+		Path:             r.cfg.Path,
+		ResourceData:     invResources,
+		PluginDir:        parser.GetPluginDir(r.cfg.Path),
+		LockFile:         parser.GetLockFile(r.cfg.Path),
+		DryRun:           r.cfg.DryRun,
+		Destroy:          true,
+		KeepGoing:        r.cfg.KeepGoing,
+		Limiter:          r.limiter,
+		ProviderLimiters: r.providerLimiters,
+	})
+	err := invkformCtx.ParseAndRun(ctx, map[string]any{})
+	return invkformCtx.getResources(), err
+}
+
+func listPackageResources(ctx context.Context, prefix string, pkgResourcesStore store.Storer[store.Storer[data.BlockData]]) {
+	log := log.FromContext(ctx)
 	if pkgResourcesStore != nil {
 		pkgResourcesStore.List(func(k store.Key, s store.Storer[data.BlockData]) {
 			pkgName := k.Name
 			s.List(func(k store.Key, bd data.BlockData) {
 				for idx, rn := range bd.Get() {
-					fmt.Println("pkgResource", prefix, pkgName, k.String(), "idx", idx, rn.GetApiVersion(), rn.GetKind(), rn.GetName(), rn.GetNamespace(), rn.GetAnnotations())
+					log.Debug("pkgResource", "prefix", prefix, "pkgName", pkgName, "block", k.String(), "idx", idx, "apiVersion", rn.GetApiVersion(), "kind", rn.GetKind(), "name", rn.GetName(), "namespace", rn.GetNamespace())
 				}
 			})
 		})
 	}
 }
 
-func getInventoryResourcesToDelete(pkgResourcesStore store.Storer[store.Storer[data.BlockData]], providers map[string]string) store.Storer[[]byte] {
+// getInventoryResources returns the resources as resource blocks together with
+// the provider configs they use, such that they can be actuated by an inventory
+// dagRun w/o rendering. The dependencies, lifecycle and sensitivity of the blocks
+// are added as annotations to retain the order, lifecycle and sensitivity of the resources.
+func getInventoryResources(pkgResourcesStore store.Storer[store.Storer[data.BlockData]], providers map[string]string, blockInfos map[string]map[string]*invv1alpha1.BlockInfo) store.Storer[[]byte] {
 	invResources := memory.NewStore[[]byte](nil)
 	usedProviders := sets.New[string]()
 	pkgResourcesStore.List(func(k store.Key, s store.Storer[data.BlockData]) {
+		pkgName := k.Name
 		s.List(func(k store.Key, bd data.BlockData) {
 			for idx, rn := range bd.Get() {
 				parts := strings.SplitN(k.Name, ".", 2)
@@ -213,6 +492,17 @@ func getInventoryResourcesToDelete(pkgResourcesStore store.Storer[store.Storer[d
 				annotations[kformv1alpha1.KformAnnotationKey_BLOCK_TYPE] = kformv1alpha1.BlockTYPE_RESOURCE.String()
 				annotations[kformv1alpha1.KformAnnotationKey_RESOURCE_TYPE] = resourceType
 				annotations[kformv1alpha1.KformAnnotationKey_RESOURCE_ID] = resourceID
+				if blockInfo := blockInfos[pkgName][k.Name]; blockInfo != nil {
+					if len(blockInfo.Dependencies) != 0 {
+						annotations[kformv1alpha1.KformAnnotationKey_DEPENDS_ON] = strings.Join(blockInfo.Dependencies, ",")
+					}
+					if blockInfo.LifeCycle != nil {
+						annotations[kformv1alpha1.KformAnnotationKey_LIFECYCLE] = blockInfo.LifeCycle.String()
+					}
+					if blockInfo.Sensitive {
+						annotations[kformv1alpha1.KformAnnotationKey_SENSITIVE] = "true"
+					}
+				}
 				rn.SetAnnotations(annotations)
 
 				usedProviders.Insert(strings.SplitN(resourceType, "_", 2)[0])
@@ -230,23 +520,35 @@ func getInventoryResourcesToDelete(pkgResourcesStore store.Storer[store.Storer[d
 	return invResources
 }
 
-// execute the diff program comparing the from path with the to path
-func diffExec(from, to string) error {
-	args := []string{"-u", "-N", from, to}
-	cmd := exec.Command("diff", args...)
-	out, err := cmd.CombinedOutput()
-	exitCode := cmd.ProcessState.ExitCode()
+// addKformFile adds the KformFile of the package at path to the inventory resources, such
+// that the provider requirements of the package apply to the providers of the inventory
+func addKformFile(path string, invResources store.Storer[[]byte]) error {
+	if path == "" {
+		return nil
+	}
+	kformFile := filepath.Join(path, kformv1alpha1.KformFileName)
+	if !fsys.FileExists(kformFile) {
+		return nil
+	}
+	b, err := os.ReadFile(kformFile)
 	if err != nil {
-		// existCode 0, no diff
-		// exitCode 1, diff
-		if exitCode > 1 {
-			fmt.Printf("Command failed with exit code %d\n", exitCode)
-			return err
-		}
+		return fmt.Errorf("cannot read %s, err: %s", kformFile, err.Error())
 	}
-	if exitCode == 1 {
-		// we only print when the exit code indicates there is a diff
-		fmt.Println(string(out))
+	return invResources.Update(store.ToKey(kformv1alpha1.KformFileName), b)
+}
+
+// out returns the writer used to print information to the user
+func (r *runner) out() io.Writer {
+	if r.cfg.IOStreams.Out != nil {
+		return r.cfg.IOStreams.Out
 	}
-	return nil
+	return os.Stdout
+}
+
+// errOut returns the writer used to print warnings to the user
+func (r *runner) errOut() io.Writer {
+	if r.cfg.IOStreams.ErrOut != nil {
+		return r.cfg.IOStreams.ErrOut
+	}
+	return os.Stderr
 }
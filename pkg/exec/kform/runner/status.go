@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"github.com/henderiw/store"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/fn/fns"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type objectKey struct {
+	pkgName string
+	ref     invv1alpha1.ObjectReference
+}
+
+// getObjectStatus returns the status of the objects recorded in the inventory: the actuated objects
+// succeeded, the other objects in scope of the run failed to actuate and the objects out of scope
+// retain the status of the inventory. The reconciliation of the objects is recorded when the
+// waiter observed it, otherwise it is skipped.
+func getObjectStatus(actuated store.Storer[store.Storer[data.BlockData]], inventory *invv1alpha1.Inventory, inScope func(pkgName, blockName string) bool, waiter *fns.Waiter) invv1alpha1.ObjectStatusFn {
+	now := metav1.Now()
+	succeeded := map[objectKey]bool{}
+	compactResources(actuated).List(func(k store.Key, s store.Storer[data.BlockData]) {
+		pkgName := k.Name
+		s.List(func(k store.Key, bd data.BlockData) {
+			for _, rn := range bd {
+				ref, err := invv1alpha1.GetObjectReference(rn)
+				if err != nil {
+					continue
+				}
+				succeeded[objectKey{pkgName: pkgName, ref: ref}] = true
+			}
+		})
+	})
+	return func(pkgName, blockName string, ref invv1alpha1.ObjectReference) invv1alpha1.ObjectStatus {
+		if inScope != nil && !inScope(pkgName, blockName) {
+			if status, ok := inventory.GetObjectStatus(pkgName, blockName, ref); ok {
+				return status
+			}
+		}
+		status := invv1alpha1.ObjectStatus{
+			Strategy:      invv1alpha1.ActuationStrategyApply,
+			Actuation:     invv1alpha1.ActuationFailed,
+			Reconcile:     invv1alpha1.ReconcileSkipped,
+			ActuationTime: &now,
+		}
+		if succeeded[objectKey{pkgName: pkgName, ref: ref}] {
+			status.Actuation = invv1alpha1.ActuationSucceeded
+			if result, ok := waiter.GetResult(pkgName, ref); ok {
+				status.Reconcile = result.Status
+				status.ReconcileTime = &result.Time
+			}
+		}
+		return status
+	}
+}
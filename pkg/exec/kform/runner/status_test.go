@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/exec/fn/fns"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestGetObjectStatus(t *testing.T) {
+	cm := func(name string) invv1alpha1.ObjectReference {
+		return invv1alpha1.ObjectReference{Version: "v1", Kind: "ConfigMap", Name: name, Namespace: "default"}
+	}
+	rn, err := yaml.Parse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n  namespace: default\n")
+	if err != nil {
+		t.Fatalf("cannot parse resource: %s", err)
+	}
+	pkgStore := memory.NewStore[data.BlockData](nil)
+	pkgStore.Create(store.ToKey("cm"), data.BlockData{rn, nil})
+	actuated := memory.NewStore[store.Storer[data.BlockData]](nil)
+	actuated.Create(store.ToKey("root"), pkgStore)
+
+	inventory := &invv1alpha1.Inventory{
+		Packages: map[string]*invv1alpha1.PackageInventory{
+			"root": {PackageResources: map[string][]invv1alpha1.Object{
+				"secret": {{ObjectRef: cm("secret1"), ObjectStatus: invv1alpha1.ObjectStatus{Actuation: invv1alpha1.ActuationSucceeded}}},
+			}},
+		},
+	}
+	inScope := func(pkgName, blockName string) bool { return blockName == "cm" }
+	// the waiter observed the actuated object to be ready
+	waiter := fns.NewWaiter(time.Second)
+	if err := waiter.Wait(context.Background(), "root", cm("cm1"), func(ctx context.Context) ([]byte, error) {
+		return rn.MarshalJSON()
+	}); err != nil {
+		t.Fatalf("unexpected wait error: %s", err)
+	}
+	statusFn := getObjectStatus(actuated, inventory, inScope, waiter)
+
+	cases := map[string]struct {
+		blockName string
+		ref       invv1alpha1.ObjectReference
+		expected  invv1alpha1.ActuationStatus
+		reconcile invv1alpha1.ReconcileStatus
+	}{
+		"Actuated": {
+			blockName: "cm",
+			ref:       cm("cm1"),
+			expected:  invv1alpha1.ActuationSucceeded,
+			reconcile: invv1alpha1.ReconcileSucceeded,
+		},
+		"NotActuated": {
+			blockName: "cm",
+			ref:       cm("cm2"),
+			expected:  invv1alpha1.ActuationFailed,
+			reconcile: invv1alpha1.ReconcileSkipped,
+		},
+		"OutOfScope": {
+			blockName: "secret",
+			ref:       cm("secret1"),
+			expected:  invv1alpha1.ActuationSucceeded,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			status := statusFn("root", tc.blockName, tc.ref)
+			if status.Actuation != tc.expected {
+				t.Errorf("want actuation %s, got: %s", tc.expected, status.Actuation)
+			}
+			if tc.blockName == "cm" {
+				if status.Strategy != invv1alpha1.ActuationStrategyApply || status.Reconcile != tc.reconcile {
+					t.Errorf("want strategy %s and reconcile %s, got: %s, %s", invv1alpha1.ActuationStrategyApply, tc.reconcile, status.Strategy, status.Reconcile)
+				}
+				if status.ActuationTime == nil {
+					t.Errorf("want actuation time, got none")
+				}
+			}
+		})
+	}
+}
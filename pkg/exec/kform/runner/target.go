@@ -0,0 +1,217 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/dag"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/syntax/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// targetFilter selects the blocks a partial run acts on, blocks are
+// identified by their name e.g. <RESOURCE_TYPE>.<RESOURCE_ID>
+type targetFilter struct {
+	targets  sets.Set[string]
+	excludes sets.Set[string]
+}
+
+func newTargetFilter(targets, excludes []string) *targetFilter {
+	return &targetFilter{
+		targets:  sets.New[string](targets...),
+		excludes: sets.New[string](excludes...),
+	}
+}
+
+// isPartial returns true when the run only acts on a subset of the blocks, nil safe
+func (r *targetFilter) isPartial() bool {
+	if r == nil {
+		return false
+	}
+	return r.targets.Len() != 0 || r.excludes.Len() != 0
+}
+
+// selectVertices returns the vertices of the package dag to run: the targets together
+// with their transitive upstream dependencies, w/o the excluded vertices and their
+// transitive downstream dependents. W/o targets all vertices are selected.
+func (r *targetFilter) selectVertices(d dag.DAG[*types.VertexContext]) sets.Set[string] {
+	selected := sets.New[string]()
+	if r.targets.Len() == 0 {
+		for vertexName := range d.GetVertices() {
+			selected.Insert(vertexName)
+		}
+	} else {
+		for target := range r.targets {
+			if d.VertexExists(target) {
+				selected = selected.Union(walkVertices(target, d.GetUpVertexes))
+			}
+		}
+	}
+	for exclude := range r.excludes {
+		if d.VertexExists(exclude) {
+			selected = selected.Difference(walkVertices(exclude, d.GetDownVertexes))
+		}
+	}
+	selected.Insert(dag.Root)
+	return selected
+}
+
+// selectInventoryBlocks returns the resource blocks of the inventory to destroy: the targets
+// together with the blocks that depend on them, w/o the excluded blocks and the blocks they
+// depend on. The dependencies of the blockInfos are transitive. W/o targets all blocks are selected.
+func (r *targetFilter) selectInventoryBlocks(blockInfos map[string]*invv1alpha1.BlockInfo) sets.Set[string] {
+	selected := sets.New[string]()
+	for blockName, blockInfo := range blockInfos {
+		if r.targets.Len() == 0 || r.targets.Has(blockName) || r.targets.HasAny(blockInfo.Dependencies...) {
+			selected.Insert(blockName)
+		}
+	}
+	for exclude := range r.excludes {
+		selected.Delete(exclude)
+		if blockInfo, ok := blockInfos[exclude]; ok {
+			selected.Delete(blockInfo.Dependencies...)
+		}
+	}
+	return selected
+}
+
+// getInventoryScope returns a function that indicates if the resources of a block
+// of the inventory are in scope of a destroy run
+func (r *targetFilter) getInventoryScope(blockInfos map[string]map[string]*invv1alpha1.BlockInfo) func(pkgName, blockName string) bool {
+	selected := map[string]sets.Set[string]{}
+	for pkgName, pkgBlockInfos := range blockInfos {
+		selected[pkgName] = r.selectInventoryBlocks(pkgBlockInfos)
+	}
+	return func(pkgName, blockName string) bool {
+		return selected[pkgName].Has(blockName)
+	}
+}
+
+// validateTargets returns an error when a target is not a known block
+func (r *targetFilter) validateTargets(blocks sets.Set[string]) error {
+	unknown := sets.List(r.targets.Difference(blocks))
+	if len(unknown) != 0 {
+		return fmt.Errorf("targets not found: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// warn tells the user the run is partial, since the state of the resources that
+// are not selected is not reconciled
+func (r *targetFilter) warn(w io.Writer) {
+	var selection []string
+	if r.targets.Len() != 0 {
+		selection = append(selection, fmt.Sprintf("targets: %s", strings.Join(sets.List(r.targets), ", ")))
+	}
+	if r.excludes.Len() != 0 {
+		selection = append(selection, fmt.Sprintf("excludes: %s", strings.Join(sets.List(r.excludes), ", ")))
+	}
+	fmt.Fprintf(w, "Warning: partial run (%s), the resources that are not selected are left untouched and may not reflect the package\n", strings.Join(selection, "; "))
+}
+
+func walkVertices(from string, next func(string) []string) sets.Set[string] {
+	visited := sets.New[string](from)
+	queue := []string{from}
+	for len(queue) != 0 {
+		vertexName := queue[0]
+		queue = queue[1:]
+		for _, n := range next(vertexName) {
+			if !visited.Has(n) {
+				visited.Insert(n)
+				queue = append(queue, n)
+			}
+		}
+	}
+	return visited
+}
+
+// pruneDAG returns a dag with the selected vertices and the edges in between them
+func pruneDAG(ctx context.Context, d dag.DAG[*types.VertexContext], selected sets.Set[string]) (dag.DAG[*types.VertexContext], error) {
+	pruned := dag.New[*types.VertexContext]()
+	vertexNames := sets.List(selected)
+	for _, vertexName := range vertexNames {
+		vertexContext, err := d.GetVertex(vertexName)
+		if err != nil {
+			return nil, err
+		}
+		if err := pruned.AddVertex(ctx, vertexName, vertexContext); err != nil {
+			return nil, err
+		}
+	}
+	for _, vertexName := range vertexNames {
+		for _, upVertexName := range d.GetUpVertexes(vertexName) {
+			if selected.Has(upVertexName) {
+				pruned.Connect(ctx, upVertexName, vertexName)
+			}
+		}
+	}
+	return pruned, nil
+}
+
+// filterResources returns the resources of the blocks for which keep returns true
+func filterResources(resources store.Storer[store.Storer[data.BlockData]], keep func(pkgName, blockName string) bool) store.Storer[store.Storer[data.BlockData]] {
+	filtered := memory.NewStore[store.Storer[data.BlockData]](nil)
+	if resources == nil {
+		return filtered
+	}
+	resources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+		pkgName := k.Name
+		pkgStore := memory.NewStore[data.BlockData](nil)
+		s.List(func(k store.Key, bd data.BlockData) {
+			if keep(pkgName, k.Name) {
+				pkgStore.Create(k, bd)
+			}
+		})
+		filtered.Create(store.ToKey(pkgName), pkgStore)
+	})
+	return filtered
+}
+
+// mergeResources returns the resources of all stores, the stores should not share blocks
+func mergeResources(resources ...store.Storer[store.Storer[data.BlockData]]) store.Storer[store.Storer[data.BlockData]] {
+	merged := memory.NewStore[store.Storer[data.BlockData]](nil)
+	for _, pkgResources := range resources {
+		if pkgResources == nil {
+			continue
+		}
+		pkgResources.List(func(k store.Key, s store.Storer[data.BlockData]) {
+			pkgStore, err := merged.Get(k)
+			if err != nil {
+				pkgStore = memory.NewStore[data.BlockData](nil)
+				merged.Create(k, pkgStore)
+			}
+			s.List(func(k store.Key, bd data.BlockData) {
+				pkgStore.Create(k, bd)
+			})
+		})
+	}
+	return merged
+}
+
+// mergeProviders returns the provider configs of the inventory overwritten by the provider configs of the run
+func mergeProviders(invProviders, providers map[string]string) map[string]string {
+	merged := make(map[string]string, len(invProviders)+len(providers))
+	for _, p := range []map[string]string{invProviders, providers} {
+		for name, config := range p {
+			merged[name] = config
+		}
+	}
+	return merged
+}
+
+// listBlocks returns the names of the blocks of all packages
+func listBlocks(blockInfos map[string]map[string]*invv1alpha1.BlockInfo) sets.Set[string] {
+	blocks := sets.New[string]()
+	for _, pkgBlockInfos := range blockInfos {
+		for blockName := range pkgBlockInfos {
+			blocks.Insert(blockName)
+		}
+	}
+	return blocks
+}
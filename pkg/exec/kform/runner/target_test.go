@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/dag"
+	"github.com/kform-dev/kform/pkg/syntax/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestSelectVertices(t *testing.T) {
+	// root -> input.ns -> ns -> deployment -> service
+	//                        -> cm
+	cases := map[string]struct {
+		targets  []string
+		excludes []string
+		expected []string
+	}{
+		"Target": {
+			targets:  []string{"deployment"},
+			expected: []string{"deployment", "input.ns", "ns", dag.Root},
+		},
+		"Exclude": {
+			excludes: []string{"deployment"},
+			expected: []string{"cm", "input.ns", "ns", dag.Root},
+		},
+		"TargetAndExclude": {
+			targets:  []string{"service", "cm"},
+			excludes: []string{"deployment"},
+			expected: []string{"cm", "input.ns", "ns", dag.Root},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			d := dag.New[*types.VertexContext]()
+			for _, v := range []string{dag.Root, "input.ns", "ns", "deployment", "service", "cm"} {
+				d.AddVertex(ctx, v, &types.VertexContext{BlockName: v})
+			}
+			d.Connect(ctx, dag.Root, "input.ns")
+			d.Connect(ctx, "input.ns", "ns")
+			d.Connect(ctx, "ns", "deployment")
+			d.Connect(ctx, "deployment", "service")
+			d.Connect(ctx, "ns", "cm")
+
+			selected := newTargetFilter(tc.targets, tc.excludes).selectVertices(d)
+			if diff := cmp.Diff(tc.expected, sets.List(selected)); diff != "" {
+				t.Errorf("-want selected, +got:\n%s", diff)
+			}
+
+			pruned, err := pruneDAG(ctx, d, selected)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.expected, sets.List(sets.KeySet(pruned.GetVertices()))); diff != "" {
+				t.Errorf("-want vertices, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelectInventoryBlocks(t *testing.T) {
+	blockInfos := map[string]*invv1alpha1.BlockInfo{
+		"ns":         {},
+		"deployment": {Dependencies: []string{"ns"}},
+		"service":    {Dependencies: []string{"deployment", "ns"}},
+		"cm":         {Dependencies: []string{"ns"}},
+	}
+	cases := map[string]struct {
+		targets  []string
+		excludes []string
+		expected []string
+	}{
+		"All": {
+			expected: []string{"cm", "deployment", "ns", "service"},
+		},
+		"Target": {
+			targets:  []string{"deployment"},
+			expected: []string{"deployment", "service"},
+		},
+		"Exclude": {
+			excludes: []string{"deployment"},
+			expected: []string{"cm", "service"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			selected := newTargetFilter(tc.targets, tc.excludes).selectInventoryBlocks(blockInfos)
+			if diff := cmp.Diff(tc.expected, sets.List(selected)); diff != "" {
+				t.Errorf("-want selected, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/henderiw/logger/log"
 	"github.com/kform-dev/kform/apis/core"
@@ -13,15 +15,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/yaml"
 )
 
-// Client expresses an interface for interacting with
+// Backend expresses an interface for interacting with
 // objects which store references to objects (inventory objects).
-type Client interface {
+type Backend interface {
 	// GetClusterInventory returns the inventory, which consists of the providers with their
 	// resp. configs and the packages with their respective objRefs;
 	// or an error if one occurred.
@@ -29,13 +33,21 @@ type Client interface {
 	// GetClusterInventoryInfo returns the cluster inventory object.
 	GetClusterInventoryInfo(ctx context.Context, inv Info) (*unstructured.Unstructured, error)
 
+	// Apply stores the inventory object, when the inventory was read before the update
+	// only succeeds if the inventory did not change in the meantime.
 	Apply(ctx context.Context, inv *unstructured.Unstructured) error
 
 	Delete(ctx context.Context, inv *unstructured.Unstructured) error
+	// Lock acquires or renews the lock of the inventory for the holder, the lock
+	// expires after the duration unless it is renewed.
+	Lock(ctx context.Context, inv Info, holder string, duration time.Duration) error
+	// Unlock releases the lock of the inventory held by the holder, an empty holder
+	// releases the lock irrespective of who holds it.
+	Unlock(ctx context.Context, inv Info, holder string) error
 }
 
-// ClusterClient is a implementation of the
-// Client interface.
+// ClusterClient is a implementation of the Backend interface, which
+// stores the inventory in a ConfigMap or a Secret in the cluster.
 type ClusterClient struct {
 	dc                    dynamic.Interface
 	discoveryClient       discovery.CachedDiscoveryInterface
@@ -44,12 +56,19 @@ type ClusterClient struct {
 	gvk                   schema.GroupVersionKind
 	invToStorageFunc      ToStorageFunc
 	invToUnstructuredFunc ToUnstructuredFunc
+	// shardSize is the max size of the compressed inventory data per object
+	shardSize int
+
+	m sync.Mutex
+	// resourceVersions are the versions of the inventory objects that were read,
+	// keyed by their namespaced name
+	resourceVersions map[string]string
 }
 
-var _ Client = &ClusterClient{}
+var _ Backend = &ClusterClient{}
 
 // NewClient returns a concrete implementation of the
-// Client interface or an error.
+// Backend interface, which stores the inventory in a ConfigMap, or an error.
 func NewClient(
 	factory util.Factory,
 	invToStorageFunc ToStorageFunc,
@@ -68,7 +87,7 @@ func NewClient(
 	if err != nil {
 		return nil, err
 	}
-	clusterClient := ClusterClient{
+	return &ClusterClient{
 		dc:                    dc,
 		discoveryClient:       discoveryClient,
 		mapper:                mapper,
@@ -76,8 +95,9 @@ func NewClient(
 		gvk:                   core.ConfigMapGVK,
 		invToStorageFunc:      invToStorageFunc,
 		invToUnstructuredFunc: invToUnstructuredFunc,
-	}
-	return &clusterClient, nil
+		shardSize:             defaultShardSize,
+		resourceVersions:      map[string]string{},
+	}, nil
 }
 
 func (r *ClusterClient) GetClusterInventory(ctx context.Context, inv Info) (*invv1alpha1.Inventory, error) {
@@ -99,27 +119,53 @@ func (r *ClusterClient) GetClusterInventoryInfo(ctx context.Context, inv Info) (
 	if localInv == nil {
 		return nil, fmt.Errorf("cannot retrieve cluster inventory object with nil local inventory")
 	}
+	localInv, err := r.toBackendObject(localInv)
+	if err != nil {
+		return nil, err
+	}
 
 	mapping, err := r.getMapping(localInv)
 	if err != nil {
 		return nil, err
 	}
 	log.Debug("fetching inventory", "nsn", inv.NamespacedName())
-	clusterInv, err := r.dc.Resource(mapping.Resource).Namespace(inv.Namespace()).
-		Get(ctx, inv.Name(), metav1.GetOptions{})
+	namespacedClient := r.dc.Resource(mapping.Resource).Namespace(inv.Namespace())
+	clusterInv, err := namespacedClient.Get(ctx, inv.Name(), metav1.GetOptions{})
 	if err != nil && !apierrors.IsNotFound(err) {
 		return nil, err
 	}
 	if apierrors.IsNotFound(err) {
 		return nil, nil
 	}
-	return clusterInv, nil
+	r.setResourceVersion(clusterInv)
+	index, err := r.fromBackendObject(clusterInv)
+	if err != nil {
+		return nil, err
+	}
+	// reassemble the inventory from its shards
+	return unshardInventory(index, func(name string) (*unstructured.Unstructured, error) {
+		shard, err := namespacedClient.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return r.fromBackendObject(shard)
+	})
 }
 
 // getMapping returns the RESTMapping for the provided resource.
 func (r *ClusterClient) Apply(ctx context.Context, inv *unstructured.Unstructured) error {
 	r.ensureKformNamespace(ctx)
 
+	// the inventory is stored compressed and sharded across multiple objects, such that
+	// it is not limited by the max size of a single object
+	index, shards, err := shardInventory(inv, r.shardSize)
+	if err != nil {
+		return err
+	}
+	inv, err = r.toBackendObject(index)
+	if err != nil {
+		return err
+	}
 	mapping, err := r.getMapping(inv)
 	if err != nil {
 		return err
@@ -127,6 +173,13 @@ func (r *ClusterClient) Apply(ctx context.Context, inv *unstructured.Unstructure
 	// Create client to interact with cluster.
 	namespacedClient := r.dc.Resource(mapping.Resource).Namespace(inv.GetNamespace())
 
+	// the shards are stored before the index, such that the index only refers to stored shards
+	for _, shard := range shards {
+		if err := r.applyShard(ctx, namespacedClient, shard); err != nil {
+			return err
+		}
+	}
+
 	// Get cluster object, if exsists.
 	clusterInvObj, err := namespacedClient.Get(ctx, inv.GetName(), metav1.GetOptions{})
 	if err != nil && !apierrors.IsNotFound(err) {
@@ -135,17 +188,86 @@ func (r *ClusterClient) Apply(ctx context.Context, inv *unstructured.Unstructure
 
 	// Create cluster inventory object, if it does not exist on cluster.
 	if clusterInvObj == nil {
-		_, err = namespacedClient.Create(ctx, inv, metav1.CreateOptions{})
+		newInvObj, err := namespacedClient.Create(ctx, inv, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+		r.setResourceVersion(newInvObj)
+		return nil
+	}
+
+	// Update the cluster inventory object instead, with the version that was read
+	// such that a concurrent change of the inventory results in a conflict
+	inv = inv.DeepCopy()
+	inv.SetResourceVersion(r.getResourceVersion(inv))
+	newInvObj, err := namespacedClient.Update(ctx, inv, metav1.UpdateOptions{})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			return fmt.Errorf("inventory %s was changed by another run: %w", inv.GetName(), err)
+		}
 		return err
 	}
+	r.setResourceVersion(newInvObj)
+	// the shards of the previous inventory are no longer referenced
+	return r.deleteShards(ctx, namespacedClient, clusterInvObj, newInvObj)
+}
 
-	// Update the cluster inventory object instead.
-	_, err = namespacedClient.Update(ctx, inv, metav1.UpdateOptions{})
+func (r *ClusterClient) applyShard(ctx context.Context, namespacedClient dynamic.ResourceInterface, shard *unstructured.Unstructured) error {
+	shard, err := r.toBackendObject(shard)
+	if err != nil {
+		return err
+	}
+	clusterShard, err := namespacedClient.Get(ctx, shard.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		_, err := namespacedClient.Create(ctx, shard, metav1.CreateOptions{})
+		return err
+	}
+	shard.SetResourceVersion(clusterShard.GetResourceVersion())
+	_, err = namespacedClient.Update(ctx, shard, metav1.UpdateOptions{})
 	return err
 }
 
+// deleteShards deletes the shards of the index, which are not referenced by the
+// current index; a nil current index deletes all the shards
+func (r *ClusterClient) deleteShards(ctx context.Context, namespacedClient dynamic.ResourceInterface, index, current *unstructured.Unstructured) error {
+	currentShards := sets.New[string]()
+	if current != nil {
+		currentShards.Insert(getShardNames(current)...)
+	}
+	for _, name := range getShardNames(index) {
+		if currentShards.Has(name) {
+			continue
+		}
+		if err := namespacedClient.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ClusterClient) setResourceVersion(inv *unstructured.Unstructured) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	nsn := types.NamespacedName{Namespace: inv.GetNamespace(), Name: inv.GetName()}.String()
+	r.resourceVersions[nsn] = inv.GetResourceVersion()
+}
+
+func (r *ClusterClient) getResourceVersion(inv *unstructured.Unstructured) string {
+	r.m.Lock()
+	defer r.m.Unlock()
+	nsn := types.NamespacedName{Namespace: inv.GetNamespace(), Name: inv.GetName()}.String()
+	return r.resourceVersions[nsn]
+}
+
 // getMapping returns the RESTMapping for the provided resource.
 func (r *ClusterClient) Delete(ctx context.Context, inv *unstructured.Unstructured) error {
+	inv, err := r.toBackendObject(inv)
+	if err != nil {
+		return err
+	}
 	mapping, err := r.getMapping(inv)
 	if err != nil {
 		return err
@@ -154,15 +276,19 @@ func (r *ClusterClient) Delete(ctx context.Context, inv *unstructured.Unstructur
 	namespacedClient := r.dc.Resource(mapping.Resource).Namespace(inv.GetNamespace())
 
 	// Get cluster object, if exsists.
-	if _, err := namespacedClient.Get(ctx, inv.GetName(), metav1.GetOptions{}); err != nil {
+	clusterInvObj, err := namespacedClient.Get(ctx, inv.GetName(), metav1.GetOptions{})
+	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			return err
 		}
 		return nil
 	}
 
-	//Delete the inventory
-	return namespacedClient.Delete(ctx, inv.GetName(), metav1.DeleteOptions{})
+	//Delete the inventory, followed by its shards
+	if err := namespacedClient.Delete(ctx, inv.GetName(), metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+	return r.deleteShards(ctx, namespacedClient, clusterInvObj, nil)
 }
 
 // getMapping returns the RESTMapping for the provided resource.
@@ -100,9 +100,9 @@ func (r *ConfigMap) Load(ctx context.Context) (*invv1alpha1.Inventory, error) {
 
 // GetObject returns the wrapped object (ConfigMap) as a resource.Info
 // or an error if one occurs.
-func (r *ConfigMap) GetObject(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]]) (*unstructured.Unstructured, error) {
+func (r *ConfigMap) GetObject(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*invv1alpha1.BlockInfo, statusFn invv1alpha1.ObjectStatusFn) (*unstructured.Unstructured, error) {
 	// Create the dataMap of all the providers and resources
-	dataMap, err := buildDataMap(ctx, providers, newActuatedResources)
+	dataMap, err := buildDataMap(ctx, providers, newActuatedResources, blockInfos, statusFn)
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +117,7 @@ func (r *ConfigMap) GetObject(ctx context.Context, providers map[string]string,
 	return invCopy, nil
 }
 
-func buildDataMap(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]]) (map[string]string, error) {
+func buildDataMap(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*invv1alpha1.BlockInfo, statusFn invv1alpha1.ObjectStatusFn) (map[string]string, error) {
 	dataMap := map[string]string{}
 	if providers != nil {
 		providerByte, err := invv1alpha1.MarshalProviders(providers)
@@ -127,7 +127,7 @@ func buildDataMap(ctx context.Context, providers map[string]string, newActuatedR
 		dataMap["providers"] = string(providerByte)
 	}
 	if newActuatedResources != nil {
-		packageByte, err := invv1alpha1.MarshalPackages(ctx, newActuatedResources)
+		packageByte, err := invv1alpha1.MarshalPackages(ctx, newActuatedResources, blockInfos, statusFn)
 		if err != nil {
 			return dataMap, err
 		}
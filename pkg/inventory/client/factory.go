@@ -1,24 +1,42 @@
 package client
 
 import (
+	"fmt"
+
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
 	"github.com/kform-dev/kform/pkg/inventory/policy"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
 
 var (
-	_ ClientFactory = ClusterClientFactory{}
+	_ BackendFactory = ConfigBackendFactory{}
 )
 
-// ClientFactory is a factory that constructs new Client instances.
-type ClientFactory interface {
-	NewClient(factory util.Factory) (Client, error)
+// BackendFactory is a factory that constructs new Backend instances.
+type BackendFactory interface {
+	NewBackend(factory util.Factory) (Backend, error)
 }
 
-// ClusterClientFactory is a factory that creates instances of ClusterClient inventory client.
-type ClusterClientFactory struct {
+// ConfigBackendFactory is a factory that creates the Backend selected by the config
+// of the backend block, w/o config the inventory is stored in a ConfigMap in the
+// cluster of the current kube context.
+type ConfigBackendFactory struct {
 	StatusPolicy policy.StatusPolicy
+	Config       *backendv1alpha1.Config
 }
 
-func (r ClusterClientFactory) NewClient(factory util.Factory) (Client, error) {
-	return NewClient(factory, WrapInventoryObj, InvInfoToConfigMap, r.StatusPolicy)
+func (r ConfigBackendFactory) NewBackend(factory util.Factory) (Backend, error) {
+	switch r.Config.GetType() {
+	case backendv1alpha1.BackendTypeConfigMap:
+		return NewClient(NewBackendClusterFactory(factory, r.Config), WrapInventoryObj, InvInfoToConfigMap, r.StatusPolicy)
+	case backendv1alpha1.BackendTypeSecret:
+		return NewSecretClient(NewBackendClusterFactory(factory, r.Config), r.StatusPolicy)
+	case backendv1alpha1.BackendTypeLocal:
+		if r.Config.Spec.Path == nil {
+			return nil, fmt.Errorf("the local backend requires a path")
+		}
+		return NewFileBackend(*r.Config.Spec.Path), nil
+	default:
+		return nil, fmt.Errorf("unsupported backend type %q", r.Config.GetType())
+	}
 }
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/henderiw/logger/log"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+// FileBackend is a implementation of the Backend interface, which stores the
+// inventory in a local file. It is meant for offline and development use,
+// the file holds a single inventory.
+type FileBackend struct {
+	path string
+}
+
+var _ Backend = &FileBackend{}
+
+// NewFileBackend returns a Backend that stores the inventory in the file at path
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{path: path}
+}
+
+func (r *FileBackend) GetClusterInventory(ctx context.Context, inv Info) (*invv1alpha1.Inventory, error) {
+	fileInv, err := r.GetClusterInventoryInfo(ctx, inv)
+	if err != nil {
+		return &invv1alpha1.Inventory{}, fmt.Errorf("failed to read inventory from file: %w", err)
+	}
+	// When nothing got applied it is normal this is nil
+	if fileInv == nil {
+		return &invv1alpha1.Inventory{}, nil
+	}
+	return WrapInventoryObj(fileInv).Load(ctx)
+}
+
+func (r *FileBackend) GetClusterInventoryInfo(ctx context.Context, inv Info) (*unstructured.Unstructured, error) {
+	log := log.FromContext(ctx)
+	log.Debug("reading inventory", "path", r.path)
+	b, err := os.ReadFile(r.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	fileInv := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(b, &fileInv.Object); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal inventory %s, err: %s", r.path, err.Error())
+	}
+	return fileInv, nil
+}
+
+func (r *FileBackend) Apply(ctx context.Context, inv *unstructured.Unstructured) error {
+	b, err := yaml.Marshal(inv.Object)
+	if err != nil {
+		return err
+	}
+	return writeFile(r.path, b)
+}
+
+func (r *FileBackend) Delete(ctx context.Context, inv *unstructured.Unstructured) error {
+	if err := os.Remove(r.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Lock acquires the lock of the inventory for the holder or renews it when the holder
+// already holds it. The lock is a lease stored in a file next to the inventory file.
+func (r *FileBackend) Lock(ctx context.Context, inv Info, holder string, duration time.Duration) error {
+	now := metav1.NowMicro()
+	b, lease, err := r.readLeaseFile()
+	if err != nil {
+		return err
+	}
+	if lease != nil {
+		if current := ptr.Deref(lease.Spec.HolderIdentity, ""); current != holder {
+			if !isExpired(lease, now.Time) {
+				return fmt.Errorf("inventory %s is locked by %s since %s, when the lock is stuck remove %s",
+					r.path, current, getAcquireTime(lease), r.lockPath())
+			}
+			// the expired lease is taken over by creating a new lease
+			if err := r.claimLease(b); err != nil {
+				return err
+			}
+			lease = nil
+		}
+	}
+	create := lease == nil
+	if create {
+		lease = &coordinationv1.Lease{}
+		lease.Spec.AcquireTime = &now
+	}
+	lease.Spec.HolderIdentity = ptr.To(holder)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(duration.Seconds()))
+	lease.Spec.RenewTime = &now
+	b, err = yaml.Marshal(lease.Spec)
+	if err != nil {
+		return err
+	}
+	if !create {
+		return writeFile(r.lockPath(), b)
+	}
+	// the lock file is created exclusively, such that only one run acquires the lock
+	if err := createFile(r.lockPath(), b); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("inventory %s is locked by another run", r.path)
+		}
+		return err
+	}
+	// verify no other run replaced the lease while it was created
+	lease, err = r.readLease()
+	if err != nil {
+		return err
+	}
+	if lease == nil || ptr.Deref(lease.Spec.HolderIdentity, "") != holder {
+		return fmt.Errorf("inventory %s is locked by another run", r.path)
+	}
+	return nil
+}
+
+// claimLease removes the expired lease, the lock file is renamed such that only one run
+// claims the lease. When the lock file no longer holds the expired lease, another run
+// took over the lease in the meantime and its lease is restored.
+func (r *FileBackend) claimLease(expired []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(r.lockPath()), filepath.Base(r.lockPath())+".expired")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(r.lockPath(), tmp.Name()); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("inventory %s is locked by another run", r.path)
+		}
+		return err
+	}
+	b, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(b, expired) {
+		if err := os.Link(tmp.Name(), r.lockPath()); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+		return fmt.Errorf("inventory %s is locked by another run", r.path)
+	}
+	return nil
+}
+
+// Unlock releases the lock of the inventory held by the holder, an empty holder
+// releases the lock irrespective of who holds it
+func (r *FileBackend) Unlock(ctx context.Context, inv Info, holder string) error {
+	lease, err := r.readLease()
+	if err != nil || lease == nil {
+		return err
+	}
+	if current := ptr.Deref(lease.Spec.HolderIdentity, ""); holder != "" && current != holder {
+		return fmt.Errorf("inventory %s is locked by %s, not by %s", r.path, current, holder)
+	}
+	if err := os.Remove(r.lockPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (r *FileBackend) lockPath() string {
+	return r.path + ".lock"
+}
+
+func (r *FileBackend) readLease() (*coordinationv1.Lease, error) {
+	_, lease, err := r.readLeaseFile()
+	return lease, err
+}
+
+// readLeaseFile returns the content of the lock file and its lease, nil when the
+// inventory is not locked
+func (r *FileBackend) readLeaseFile() ([]byte, *coordinationv1.Lease, error) {
+	b, err := os.ReadFile(r.lockPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	lease := &coordinationv1.Lease{}
+	if err := yaml.Unmarshal(b, &lease.Spec); err != nil {
+		return nil, nil, fmt.Errorf("cannot unmarshal inventory lock %s, err: %s", r.lockPath(), err.Error())
+	}
+	return b, lease, nil
+}
+
+// createFile creates the file with its content, a temporary file is linked to the path
+// such that the file is only created when it does not exist and a reader never observes
+// a partially written file
+func createFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Link(tmp.Name(), path)
+}
+
+// writeFile writes the file through a temporary file, such that a reader never
+// observes a partially written file
+func writeFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
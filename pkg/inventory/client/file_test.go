@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kform-dev/kform/pkg/inventory/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestFileBackend(t *testing.T) {
+	tests := map[string]struct {
+		path string
+	}{
+		"Empty": {
+			path: "testfiles/inv1.yaml",
+		},
+		"Single": {
+			path: "testfiles/inv2.yaml",
+		},
+		"Double": {
+			path: "testfiles/inv3.yaml",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			inv := &unstructured.Unstructured{}
+			b, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if err := yaml.Unmarshal(b, inv); err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			expected, err := WrapInventoryObj(inv).Load(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			backend := NewFileBackend(filepath.Join(t.TempDir(), ".kform", "state.yaml"))
+			invInfo := WrapInventoryInfoObj(inv)
+			if err := backend.Apply(ctx, inv); err != nil {
+				t.Fatalf("unexpected apply error: %s", err.Error())
+			}
+			storedInv, err := backend.GetClusterInventory(ctx, invInfo)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if diff := cmp.Diff(expected, storedInv); diff != "" {
+				t.Errorf("-want inventory, +got:\n%s", diff)
+			}
+
+			if err := backend.Delete(ctx, inv); err != nil {
+				t.Fatalf("unexpected delete error: %s", err.Error())
+			}
+			storedInv, err = backend.GetClusterInventory(ctx, invInfo)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if len(storedInv.Packages) != 0 || len(storedInv.Providers) != 0 {
+				t.Errorf("want empty inventory after delete, got: %v", storedInv)
+			}
+		})
+	}
+}
+
+func TestFileBackendLock(t *testing.T) {
+	ctx := context.Background()
+	backend := NewFileBackend(filepath.Join(t.TempDir(), "state.yaml"))
+	inv := WrapInventoryInfoObj(config.GetFakeInventoryInfo("test"))
+
+	if err := backend.Lock(ctx, inv, "a", time.Minute); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := backend.Lock(ctx, inv, "a", time.Minute); err != nil {
+		t.Errorf("unexpected renew error: %s", err)
+	}
+	if err := backend.Lock(ctx, inv, "b", time.Minute); err == nil {
+		t.Errorf("want lock error, got none")
+	}
+	if err := backend.Unlock(ctx, inv, "b"); err == nil {
+		t.Errorf("want unlock error, got none")
+	}
+	if err := backend.Unlock(ctx, inv, "a"); err != nil {
+		t.Fatalf("unexpected unlock error: %s", err)
+	}
+	if err := backend.Lock(ctx, inv, "b", time.Minute); err != nil {
+		t.Errorf("unexpected error after unlock: %s", err)
+	}
+}
+
+func TestFileBackendLockTakeOver(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	inv := WrapInventoryInfoObj(config.GetFakeInventoryInfo("test"))
+
+	for i := 0; i < 100; i++ {
+		// a lease w/o duration expires immediately
+		if err := NewFileBackend(path).Lock(ctx, inv, "expired", 0); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		time.Sleep(time.Millisecond)
+
+		// concurrent runs take over the expired lease, only one of them acquires it
+		var wg sync.WaitGroup
+		var m sync.Mutex
+		locked := []string{}
+		for _, holder := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := NewFileBackend(path).Lock(ctx, inv, holder, time.Minute); err == nil {
+					m.Lock()
+					locked = append(locked, holder)
+					m.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if len(locked) != 1 {
+			t.Fatalf("want a single holder of the lock, got: %v", locked)
+		}
+		if err := NewFileBackend(path).Unlock(ctx, inv, locked[0]); err != nil {
+			t.Fatalf("unexpected unlock error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/henderiw/logger/log"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/ptr"
+)
+
+var leaseGVK = coordinationv1.SchemeGroupVersion.WithKind("Lease")
+
+// Lock acquires the lock of the inventory for the holder or renews it when the holder
+// already holds it. The lock is a Lease with the name of the inventory, which expires
+// after the duration unless it is renewed, such that a crashed run does not block
+// the inventory forever.
+func (r *ClusterClient) Lock(ctx context.Context, inv Info, holder string, duration time.Duration) error {
+	log := log.FromContext(ctx).With("nsn", inv.NamespacedName())
+	r.ensureKformNamespace(ctx)
+
+	client, err := r.leaseClient(inv)
+	if err != nil {
+		return err
+	}
+	now := metav1.NowMicro()
+	u, err := client.Get(ctx, inv.Name(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		lease := &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: inv.Name(), Namespace: inv.Namespace()},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(holder),
+				LeaseDurationSeconds: ptr.To(int32(duration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		u, err := toUnstructured(lease)
+		if err != nil {
+			return err
+		}
+		if _, err := client.Create(ctx, u, metav1.CreateOptions{}); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return fmt.Errorf("inventory %s is locked by another run", inv.NamespacedName())
+			}
+			return err
+		}
+		log.Debug("inventory locked", "holder", holder)
+		return nil
+	}
+
+	lease := &coordinationv1.Lease{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, lease); err != nil {
+		return err
+	}
+	if current := ptr.Deref(lease.Spec.HolderIdentity, ""); current != holder {
+		if !isExpired(lease, now.Time) {
+			return fmt.Errorf("inventory %s is locked by %s since %s, when the lock is stuck release it with: kform force-unlock %s",
+				inv.NamespacedName(), current, getAcquireTime(lease), inv.Name())
+		}
+		log.Info("taking over expired inventory lock", "holder", current)
+		lease.Spec.AcquireTime = &now
+	}
+	lease.Spec.HolderIdentity = ptr.To(holder)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(duration.Seconds()))
+	lease.Spec.RenewTime = &now
+	u, err = toUnstructured(lease)
+	if err != nil {
+		return err
+	}
+	// the update carries the resourceVersion of the lease we read, so a concurrent
+	// acquisition results in a conflict
+	if _, err := client.Update(ctx, u, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return fmt.Errorf("inventory %s is locked by another run", inv.NamespacedName())
+		}
+		return err
+	}
+	return nil
+}
+
+// Unlock releases the lock of the inventory held by the holder, an empty holder
+// releases the lock irrespective of who holds it
+func (r *ClusterClient) Unlock(ctx context.Context, inv Info, holder string) error {
+	client, err := r.leaseClient(inv)
+	if err != nil {
+		return err
+	}
+	u, err := client.Get(ctx, inv.Name(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	lease := &coordinationv1.Lease{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, lease); err != nil {
+		return err
+	}
+	if current := ptr.Deref(lease.Spec.HolderIdentity, ""); holder != "" && current != holder {
+		return fmt.Errorf("inventory %s is locked by %s, not by %s", inv.NamespacedName(), current, holder)
+	}
+	err = client.Delete(ctx, inv.Name(), metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: ptr.To(u.GetResourceVersion())},
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *ClusterClient) leaseClient(inv Info) (dynamic.ResourceInterface, error) {
+	mapping, err := r.mapper.RESTMapping(leaseGVK.GroupKind(), leaseGVK.Version)
+	if err != nil {
+		return nil, err
+	}
+	return r.dc.Resource(mapping.Resource).Namespace(inv.Namespace()), nil
+}
+
+func isExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second).Before(now)
+}
+
+func getAcquireTime(lease *coordinationv1.Lease) string {
+	if lease.Spec.AcquireTime == nil {
+		return "unknown"
+	}
+	return lease.Spec.AcquireTime.Format(time.RFC3339)
+}
+
+func toUnstructured(lease *coordinationv1.Lease) (*unstructured.Unstructured, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(lease)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: obj}
+	u.SetGroupVersionKind(leaseGVK)
+	return u, nil
+}
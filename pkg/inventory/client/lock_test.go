@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kform-dev/kform/apis/core"
+	"github.com/kform-dev/kform/pkg/inventory/config"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestLock(t *testing.T) {
+	cases := map[string]struct {
+		holder      string
+		duration    time.Duration
+		nextHolder  string
+		expectedErr bool
+	}{
+		"Renew": {
+			holder:     "a",
+			duration:   time.Minute,
+			nextHolder: "a",
+		},
+		"Locked": {
+			holder:      "a",
+			duration:    time.Minute,
+			nextHolder:  "b",
+			expectedErr: true,
+		},
+		"Expired": {
+			holder:     "a",
+			duration:   0,
+			nextHolder: "b",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := newFakeClusterClient()
+			inv := WrapInventoryInfoObj(config.GetFakeInventoryInfo("test"))
+
+			if err := r.Lock(ctx, inv, tc.holder, tc.duration); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			// a lease w/o duration expires immediately
+			time.Sleep(time.Millisecond)
+			err := r.Lock(ctx, inv, tc.nextHolder, tc.duration)
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("want error %t, got: %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr {
+				// only the holder can unlock, unless forced
+				if err := r.Unlock(ctx, inv, tc.nextHolder); err == nil {
+					t.Errorf("want unlock error, got none")
+				}
+				if err := r.Unlock(ctx, inv, ""); err != nil {
+					t.Fatalf("unexpected force unlock error: %s", err)
+				}
+			} else if err := r.Unlock(ctx, inv, tc.nextHolder); err != nil {
+				t.Fatalf("unexpected unlock error: %s", err)
+			}
+			// the inventory can be locked again after it is unlocked
+			if err := r.Lock(ctx, inv, "c", time.Minute); err != nil {
+				t.Errorf("unexpected error after unlock: %s", err)
+			}
+		})
+	}
+}
+
+func newFakeClusterClient() *ClusterClient {
+	scheme := runtime.NewScheme()
+	_ = coordinationv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(leaseGVK, meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(core.ConfigMapGVK, meta.RESTScopeNamespace)
+	mapper.Add(core.SecretGVK, meta.RESTScopeNamespace)
+	return &ClusterClient{
+		dc:                    fake.NewSimpleDynamicClient(scheme),
+		mapper:                mapper,
+		gvk:                   core.ConfigMapGVK,
+		invToStorageFunc:      WrapInventoryObj,
+		invToUnstructuredFunc: InvInfoToConfigMap,
+		resourceVersions:      map[string]string{},
+	}
+}
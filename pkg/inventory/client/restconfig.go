@@ -0,0 +1,137 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewBackendClusterFactory returns the factory that connects to the cluster that stores
+// the inventory. When the backend config specifies how to connect to the cluster the
+// factory is build from it, such that the inventory cluster is independent of the kube
+// context of the user, otherwise the supplied factory is returned.
+func NewBackendClusterFactory(factory util.Factory, backendConfig *backendv1alpha1.Config) util.Factory {
+	if !backendConfig.HasClusterConfig() {
+		return factory
+	}
+	return util.NewFactory(&restClientGetter{clientConfig: newClientConfig(backendConfig)})
+}
+
+// newClientConfig returns the client config of the backend config. The kube config files
+// are only loaded when the backend config refers to them, the other attributes override
+// the cluster and user of the loaded context.
+func newClientConfig(backendConfig *backendv1alpha1.Config) clientcmd.ClientConfig {
+	spec := backendConfig.Spec
+	loadingRules := &clientcmd.ClientConfigLoadingRules{}
+	if backendConfig.UsesKubeConfig() {
+		loadingRules = clientcmd.NewDefaultClientConfigLoadingRules()
+		if len(spec.ConfigPaths) > 0 {
+			loadingRules.Precedence = make([]string, 0, len(spec.ConfigPaths))
+			for _, path := range spec.ConfigPaths {
+				loadingRules.Precedence = append(loadingRules.Precedence, expandHomeDir(path))
+			}
+		} else if spec.ConfigPath != nil {
+			loadingRules.ExplicitPath = expandHomeDir(*spec.ConfigPath)
+		}
+	}
+
+	overrides := &clientcmd.ConfigOverrides{}
+	if spec.ConfigContext != nil {
+		overrides.CurrentContext = *spec.ConfigContext
+	}
+	if spec.ConfigContextAuthInfo != nil {
+		overrides.Context.AuthInfo = *spec.ConfigContextAuthInfo
+	}
+	if spec.ConfigContextCluster != nil {
+		overrides.Context.Cluster = *spec.ConfigContextCluster
+	}
+	if spec.Host != nil {
+		overrides.ClusterInfo.Server = *spec.Host
+	}
+	if spec.Insecure != nil {
+		overrides.ClusterInfo.InsecureSkipTLSVerify = *spec.Insecure
+	}
+	if spec.TLSServerName != nil {
+		overrides.ClusterInfo.TLSServerName = *spec.TLSServerName
+	}
+	if spec.ClusterCACertificate != nil {
+		overrides.ClusterInfo.CertificateAuthorityData = []byte(*spec.ClusterCACertificate)
+	}
+	if spec.ProxyURL != nil {
+		overrides.ClusterInfo.ProxyURL = *spec.ProxyURL
+	}
+	if spec.ClientCertificate != nil {
+		overrides.AuthInfo.ClientCertificateData = []byte(*spec.ClientCertificate)
+	}
+	if spec.ClientKey != nil {
+		overrides.AuthInfo.ClientKeyData = []byte(*spec.ClientKey)
+	}
+	if spec.Token != nil {
+		overrides.AuthInfo.Token = *spec.Token
+	}
+	if spec.Username != nil {
+		overrides.AuthInfo.Username = *spec.Username
+	}
+	if spec.Password != nil {
+		overrides.AuthInfo.Password = *spec.Password
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+}
+
+func expandHomeDir(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// restClientGetter implements the RESTClientGetter interface with the client config
+// of the backend config
+type restClientGetter struct {
+	clientConfig clientcmd.ClientConfig
+}
+
+var _ genericclioptions.RESTClientGetter = &restClientGetter{}
+
+func (r *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return r.clientConfig.ClientConfig()
+}
+
+func (r *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	config, err := r.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(dc), nil
+}
+
+func (r *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	dc, err := r.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(dc)
+	return restmapper.NewShortcutExpander(mapper, dc, nil), nil
+}
+
+func (r *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return r.clientConfig
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/ptr"
+)
+
+func TestNewClientConfig(t *testing.T) {
+	kubeConfig := clientcmdapi.NewConfig()
+	kubeConfig.Clusters["a"] = &clientcmdapi.Cluster{Server: "https://a:6443"}
+	kubeConfig.Clusters["b"] = &clientcmdapi.Cluster{Server: "https://b:6443"}
+	kubeConfig.AuthInfos["user"] = &clientcmdapi.AuthInfo{Token: "kubeconfig-token"}
+	kubeConfig.Contexts["a"] = &clientcmdapi.Context{Cluster: "a", AuthInfo: "user"}
+	kubeConfig.Contexts["b"] = &clientcmdapi.Context{Cluster: "b", AuthInfo: "user"}
+	kubeConfig.CurrentContext = "a"
+	kubeConfigPath := filepath.Join(t.TempDir(), "config")
+	if err := clientcmd.WriteToFile(*kubeConfig, kubeConfigPath); err != nil {
+		t.Fatalf("cannot write kubeconfig: %s", err)
+	}
+
+	cases := map[string]struct {
+		spec          backendv1alpha1.ConfigSpec
+		expectedHost  string
+		expectedToken string
+	}{
+		"Host": {
+			spec: backendv1alpha1.ConfigSpec{
+				Host:     ptr.To("https://inventory:6443"),
+				Token:    ptr.To("token"),
+				Insecure: ptr.To(true),
+			},
+			expectedHost:  "https://inventory:6443",
+			expectedToken: "token",
+		},
+		"ConfigContext": {
+			spec: backendv1alpha1.ConfigSpec{
+				ConfigPath:    ptr.To(kubeConfigPath),
+				ConfigContext: ptr.To("b"),
+			},
+			expectedHost:  "https://b:6443",
+			expectedToken: "kubeconfig-token",
+		},
+		"ConfigOverride": {
+			spec: backendv1alpha1.ConfigSpec{
+				ConfigPaths: []string{kubeConfigPath},
+				Token:       ptr.To("token"),
+			},
+			expectedHost:  "https://a:6443",
+			expectedToken: "token",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			backendConfig := &backendv1alpha1.Config{Spec: tc.spec}
+			if !backendConfig.HasClusterConfig() {
+				t.Fatalf("want cluster config")
+			}
+			restConfig, err := newClientConfig(backendConfig).ClientConfig()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if restConfig.Host != tc.expectedHost {
+				t.Errorf("want host %s, got: %s", tc.expectedHost, restConfig.Host)
+			}
+			if restConfig.BearerToken != tc.expectedToken {
+				t.Errorf("want token %s, got: %s", tc.expectedToken, restConfig.BearerToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/kform-dev/kform/apis/core"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/inventory/policy"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewSecretClient returns a concrete implementation of the Backend interface,
+// which stores the inventory in a Secret, or an error.
+func NewSecretClient(factory util.Factory, statusPolicy policy.StatusPolicy) (*ClusterClient, error) {
+	clusterClient, err := NewClient(factory, WrapInventoryObj, InvInfoToConfigMap, statusPolicy)
+	if err != nil {
+		return nil, err
+	}
+	clusterClient.gvk = core.SecretGVK
+	return clusterClient, nil
+}
+
+// toBackendObject converts the inventory ConfigMap to the object stored by the backend
+func (r *ClusterClient) toBackendObject(inv *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if r.gvk != core.SecretGVK {
+		return inv, nil
+	}
+	secret := inv.DeepCopy()
+	secret.SetGroupVersionKind(core.SecretGVK)
+	data, _, err := unstructured.NestedStringMap(inv.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving inventory data, err: %s", err)
+	}
+	encoded := make(map[string]any, len(data))
+	for k, v := range data {
+		encoded[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	// binary data is already encoded
+	binaryData, _, err := unstructured.NestedStringMap(inv.Object, "binaryData")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving inventory data, err: %s", err)
+	}
+	for k, v := range binaryData {
+		encoded[k] = v
+	}
+	unstructured.RemoveNestedField(secret.Object, "binaryData")
+	if len(encoded) != 0 {
+		if err := unstructured.SetNestedMap(secret.Object, encoded, "data"); err != nil {
+			return nil, err
+		}
+	}
+	if err := unstructured.SetNestedField(secret.Object, "Opaque", "type"); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// fromBackendObject converts the object stored by the backend to the inventory ConfigMap
+func (r *ClusterClient) fromBackendObject(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if r.gvk != core.SecretGVK {
+		return obj, nil
+	}
+	inv := obj.DeepCopy()
+	inv.SetGroupVersionKind(core.ConfigMapGVK)
+	unstructured.RemoveNestedField(inv.Object, "type")
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving inventory data, err: %s", err)
+	}
+	// the data of an encoded inventory is binary, which is retained as is
+	if obj.GetAnnotations()[invv1alpha1.InventoryEncodingKey] != "" {
+		unstructured.RemoveNestedField(inv.Object, "data")
+		if len(data) != 0 {
+			if err := unstructured.SetNestedStringMap(inv.Object, data, "binaryData"); err != nil {
+				return nil, err
+			}
+		}
+		return inv, nil
+	}
+	decoded := make(map[string]any, len(data))
+	for k, v := range data {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode inventory data %s, err: %s", k, err)
+		}
+		decoded[k] = string(b)
+	}
+	if len(decoded) != 0 {
+		if err := unstructured.SetNestedMap(inv.Object, decoded, "data"); err != nil {
+			return nil, err
+		}
+	}
+	return inv, nil
+}
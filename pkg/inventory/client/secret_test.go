@@ -0,0 +1,46 @@
+package client
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kform-dev/kform/apis/core"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSecretConversion(t *testing.T) {
+	inv := &unstructured.Unstructured{}
+	b, err := os.ReadFile("testfiles/inv3.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if err := yaml.Unmarshal(b, inv); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	r := &ClusterClient{gvk: core.SecretGVK}
+	secret, err := r.toBackendObject(inv)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if secret.GroupVersionKind() != core.SecretGVK {
+		t.Errorf("want %s, got: %s", core.SecretGVK, secret.GroupVersionKind())
+	}
+	data, _, _ := unstructured.NestedStringMap(inv.Object, "data")
+	secretData, _, _ := unstructured.NestedStringMap(secret.Object, "data")
+	for k, v := range data {
+		if secretData[k] == v {
+			t.Errorf("want encoded data for %s, got: %s", k, secretData[k])
+		}
+	}
+
+	got, err := r.fromBackendObject(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if diff := cmp.Diff(inv.Object, got.Object); diff != "" {
+		t.Errorf("-want inventory, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// inventoryEncodingGzip indicates the inventory data is stored gzip compressed
+	inventoryEncodingGzip = "gzip"
+	// inventoryDataKey is the key of the compressed inventory data in the binaryData of
+	// the index or shard object
+	inventoryDataKey = "inventory"
+	// defaultShardSize is the max size of the compressed inventory data per object, which
+	// leaves room for the base64 encoding and the metadata within the 1MiB object limit
+	defaultShardSize = 512 * 1024
+)
+
+// shardInventory compresses the data of the inventory ConfigMap and splits it in shards of
+// shardSize. When the compressed data fits in a single shard it is stored in the index
+// object, which retains the name of the inventory, otherwise the index lists the shard
+// objects that hold the data. The shard names are derived from the content, such that a
+// new set of shards only becomes active when the index referring to them is stored.
+func shardInventory(inv *unstructured.Unstructured, shardSize int) (*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	dataMap, _, err := unstructured.NestedStringMap(inv.Object, "data")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving inventory data, err: %s", err)
+	}
+	b, err := json.Marshal(dataMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, nil, err
+	}
+	payload := buf.Bytes()
+	if shardSize <= 0 {
+		shardSize = defaultShardSize
+	}
+
+	index := inv.DeepCopy()
+	unstructured.RemoveNestedField(index.Object, "data")
+	annotations := index.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[invv1alpha1.InventoryEncodingKey] = inventoryEncodingGzip
+	delete(annotations, invv1alpha1.InventoryShardsKey)
+
+	if len(payload) <= shardSize {
+		index.SetAnnotations(annotations)
+		if err := setInventoryData(index, payload); err != nil {
+			return nil, nil, err
+		}
+		return index, nil, nil
+	}
+
+	hash := sha256.Sum256(payload)
+	shards := []*unstructured.Unstructured{}
+	shardNames := []string{}
+	for i := 0; len(payload) > 0; i++ {
+		n := min(shardSize, len(payload))
+		shard := &unstructured.Unstructured{}
+		shard.SetGroupVersionKind(inv.GroupVersionKind())
+		shard.SetName(fmt.Sprintf("%s-%s-%d", inv.GetName(), hex.EncodeToString(hash[:4]), i))
+		shard.SetNamespace(inv.GetNamespace())
+		shard.SetLabels(inv.GetLabels())
+		shard.SetAnnotations(map[string]string{invv1alpha1.InventoryEncodingKey: inventoryEncodingGzip})
+		if err := setInventoryData(shard, payload[:n]); err != nil {
+			return nil, nil, err
+		}
+		shards = append(shards, shard)
+		shardNames = append(shardNames, shard.GetName())
+		payload = payload[n:]
+	}
+	annotations[invv1alpha1.InventoryShardsKey] = strings.Join(shardNames, ",")
+	index.SetAnnotations(annotations)
+	return index, shards, nil
+}
+
+// unshardInventory reassembles the inventory ConfigMap from the index object and its shards,
+// which are retrieved with the getShard function. An index that is not encoded is an
+// inventory stored w/o compression and is returned as is.
+func unshardInventory(index *unstructured.Unstructured, getShard func(name string) (*unstructured.Unstructured, error)) (*unstructured.Unstructured, error) {
+	if index.GetAnnotations()[invv1alpha1.InventoryEncodingKey] != inventoryEncodingGzip {
+		return index, nil
+	}
+	var payload []byte
+	shardNames := getShardNames(index)
+	if len(shardNames) == 0 {
+		b, err := getInventoryData(index)
+		if err != nil {
+			return nil, err
+		}
+		payload = b
+	}
+	for _, name := range shardNames {
+		shard, err := getShard(name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get inventory shard %s, err: %s", name, err)
+		}
+		b, err := getInventoryData(shard)
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, b...)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress inventory %s, err: %s", index.GetName(), err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress inventory %s, err: %s", index.GetName(), err)
+	}
+	dataMap := map[string]string{}
+	if err := json.Unmarshal(b, &dataMap); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal inventory %s, err: %s", index.GetName(), err)
+	}
+
+	inv := index.DeepCopy()
+	unstructured.RemoveNestedField(inv.Object, "binaryData")
+	annotations := inv.GetAnnotations()
+	delete(annotations, invv1alpha1.InventoryEncodingKey)
+	delete(annotations, invv1alpha1.InventoryShardsKey)
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(inv.Object, "metadata", "annotations")
+	} else {
+		inv.SetAnnotations(annotations)
+	}
+	if len(dataMap) != 0 {
+		if err := unstructured.SetNestedStringMap(inv.Object, dataMap, "data"); err != nil {
+			return nil, err
+		}
+	}
+	return inv, nil
+}
+
+// getShardNames returns the names of the shard objects listed by the index object
+func getShardNames(index *unstructured.Unstructured) []string {
+	shards := index.GetAnnotations()[invv1alpha1.InventoryShardsKey]
+	if shards == "" {
+		return nil
+	}
+	return strings.Split(shards, ",")
+}
+
+func setInventoryData(obj *unstructured.Unstructured, b []byte) error {
+	return unstructured.SetNestedField(obj.Object, base64.StdEncoding.EncodeToString(b), "binaryData", inventoryDataKey)
+}
+
+func getInventoryData(obj *unstructured.Unstructured) ([]byte, error) {
+	s, _, err := unstructured.NestedString(obj.Object, "binaryData", inventoryDataKey)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving inventory data of %s, err: %s", obj.GetName(), err)
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode inventory data of %s, err: %s", obj.GetName(), err)
+	}
+	return b, nil
+}
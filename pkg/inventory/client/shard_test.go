@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/kform-dev/kform/apis/core"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/inventory/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func TestShardInventory(t *testing.T) {
+	cases := map[string]struct {
+		shardSize      int
+		expectedShards bool
+	}{
+		"Inline": {
+			shardSize: defaultShardSize,
+		},
+		"Sharded": {
+			shardSize:      64,
+			expectedShards: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			inv := readInventory(t, "testfiles/inv3.yaml")
+			index, shards, err := shardInventory(inv, tc.shardSize)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.expectedShards != (len(shards) > 1) {
+				t.Fatalf("want shards %t, got: %d", tc.expectedShards, len(shards))
+			}
+			if _, ok, _ := unstructured.NestedStringMap(index.Object, "data"); ok {
+				t.Errorf("want no uncompressed data in the index")
+			}
+			got, err := unshardInventory(index, func(name string) (*unstructured.Unstructured, error) {
+				for _, shard := range shards {
+					if shard.GetName() == name {
+						return shard, nil
+					}
+				}
+				return nil, os.ErrNotExist
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(inv.Object, got.Object); diff != "" {
+				t.Errorf("-want inventory, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestShardedApply(t *testing.T) {
+	cases := map[string]struct {
+		gvk schema.GroupVersionKind
+	}{
+		"ConfigMap": {
+			gvk: core.ConfigMapGVK,
+		},
+		"Secret": {
+			gvk: core.SecretGVK,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := newFakeClusterClient()
+			r.gvk = tc.gvk
+			r.shardSize = 64
+			info := WrapInventoryInfoObj(config.GetFakeInventoryInfo("test"))
+
+			inv := readInventory(t, "testfiles/inv3.yaml")
+			if err := r.Apply(ctx, inv); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			storedInv, err := r.GetClusterInventory(ctx, info)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(storedInv.Providers) != 2 || len(storedInv.Packages) != 2 {
+				t.Errorf("want 2 providers and 2 packages, got: %d, %d", len(storedInv.Providers), len(storedInv.Packages))
+			}
+			index, err := r.getClusterObject(ctx, "test")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			oldShards := getShardNames(index)
+			if len(oldShards) < 2 {
+				t.Fatalf("want multiple shards, got: %v", oldShards)
+			}
+
+			// the shards of the previous inventory are deleted on apply
+			if err := r.Apply(ctx, readInventory(t, "testfiles/inv1.yaml")); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for _, name := range oldShards {
+				if _, err := r.getClusterObject(ctx, name); !apierrors.IsNotFound(err) {
+					t.Errorf("want shard %s deleted, got: %v", name, err)
+				}
+			}
+			storedInv, err = r.GetClusterInventory(ctx, info)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(storedInv.Providers) != 0 || len(storedInv.Packages) != 0 {
+				t.Errorf("want empty inventory, got: %d providers, %d packages", len(storedInv.Providers), len(storedInv.Packages))
+			}
+		})
+	}
+}
+
+func TestLoadUncompressed(t *testing.T) {
+	ctx := context.Background()
+	r := newFakeClusterClient()
+	inv := readInventory(t, "testfiles/inv2.yaml")
+	mapping, err := r.getMapping(inv)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// an inventory stored w/o compression is read as is
+	if _, err := r.dc.Resource(mapping.Resource).Namespace(inv.GetNamespace()).Create(ctx, inv, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	storedInv, err := r.GetClusterInventory(ctx, WrapInventoryInfoObj(config.GetFakeInventoryInfo("test")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(storedInv.Providers) != 1 || len(storedInv.Packages) != 1 {
+		t.Errorf("want 1 provider and 1 package, got: %d, %d", len(storedInv.Providers), len(storedInv.Packages))
+	}
+}
+
+func readInventory(t *testing.T, path string) *unstructured.Unstructured {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	inv := config.GetFakeInventoryInfo("test")
+	if err := yaml.Unmarshal(b, &inv.Object); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	inv.SetName("test")
+	inv.SetNamespace(config.GetFakeInventoryInfo("test").GetNamespace())
+	inv.SetLabels(map[string]string{invv1alpha1.InventoryLabelKey: "test"})
+	return inv
+}
+
+func (r *ClusterClient) getClusterObject(ctx context.Context, name string) (*unstructured.Unstructured, error) {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(r.gvk)
+	mapping, err := r.getMapping(u)
+	if err != nil {
+		return nil, err
+	}
+	return r.dc.Resource(mapping.Resource).Namespace(config.GetFakeInventoryInfo(name).GetNamespace()).Get(ctx, name, metav1.GetOptions{})
+}
@@ -14,7 +14,7 @@ import (
 // operations.
 type Storage interface {
 	// GetObject returns the object that stores the inventory
-	GetObject(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]]) (*unstructured.Unstructured, error)
+	GetObject(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*invv1alpha1.BlockInfo, statusFn invv1alpha1.ObjectStatusFn) (*unstructured.Unstructured, error)
 	// Load retrieves the set of object metadata from the inventory object
 	Load(ctx context.Context) (*invv1alpha1.Inventory, error)
 }
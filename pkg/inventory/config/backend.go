@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/pkgio"
+	"github.com/kform-dev/kform/pkg/render2"
+	"github.com/kform-dev/kform/pkg/render2/celrenderer"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// the local backend stores the inventory by default in
+	// <PKG-DIR>/.kform/kform-inventory-state.yaml
+	kformInventoryStateFilename = "kform-inventory-state.yaml"
+)
+
+// GetBackendConfig returns the config of the backend block of the package at path or of
+// the package data, when the package has no backend block the ConfigMap backend is used.
+// References to inputs in the backend block are rendered with the inputVars of the run,
+// falling back to the defaults of the input blocks of the package.
+// The path of the local backend is resolved relative to the package directory.
+func GetBackendConfig(ctx context.Context, path string, pkgData store.Storer[[]byte], inputVars map[string]any) (*backendv1alpha1.Config, error) {
+	var kformDataStore store.Storer[*yaml.RNode]
+	var err error
+	if pkgData != nil {
+		reader := pkgio.KformMemReader{Data: pkgData}
+		kformDataStore, err = reader.Read(ctx)
+	} else {
+		reader := pkgio.KformDirReader{Path: path}
+		kformDataStore, err = reader.Read(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backendRn *yaml.RNode
+	inputs := map[string]data.BlockData{}
+	kformDataStore.List(func(k store.Key, rn *yaml.RNode) {
+		annotations := rn.GetAnnotations()
+		switch annotations[kformv1alpha1.KformAnnotationKey_BLOCK_TYPE] {
+		case kformv1alpha1.BlockType_BACKEND.String():
+			backendRn = rn
+		case kformv1alpha1.BlockTYPE_INPUT.String():
+			name := annotations[kformv1alpha1.KformAnnotationKey_RESOURCE_ID]
+			if name == "" {
+				name = rn.GetName()
+			}
+			blockName := fmt.Sprintf("%s.%s", kformv1alpha1.BlockTYPE_INPUT.String(), name)
+			inputs[blockName] = inputs[blockName].Add(rn)
+		}
+	})
+
+	backendConfig := &backendv1alpha1.Config{}
+	if backendRn != nil {
+		rn, err := renderBackend(ctx, backendRn, inputs, inputVars)
+		if err != nil {
+			return nil, fmt.Errorf("cannot render backend %s, err: %s", backendRn.GetName(), err.Error())
+		}
+		b, err := rn.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, backendConfig); err != nil {
+			return nil, fmt.Errorf("invalid backend %s, err: %s", backendRn.GetName(), err.Error())
+		}
+	}
+	if err := backendConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if backendConfig.GetType() == backendv1alpha1.BackendTypeLocal {
+		statePath := filepath.Join(kformInventoryManifestFileSubDir, kformInventoryStateFilename)
+		if backendConfig.Spec.Path != nil {
+			statePath = *backendConfig.Spec.Path
+		}
+		if !filepath.IsAbs(statePath) {
+			statePath = filepath.Join(path, statePath)
+		}
+		backendConfig.Spec.Path = &statePath
+	}
+	return backendConfig, nil
+}
+
+// renderBackend renders the references to inputs in the backend block. Only the values that
+// reference an input are rendered, such that literal values like urls and certificates are
+// retained as is.
+func renderBackend(ctx context.Context, rn *yaml.RNode, inputs map[string]data.BlockData, inputVars map[string]any) (*yaml.RNode, error) {
+	varStore := memory.NewStore[data.VarData](nil)
+	for blockName, blockData := range inputs {
+		varData, err := blockData.GetVarData()
+		if err != nil {
+			return nil, err
+		}
+		varStore.Update(store.ToKey(blockName), varData)
+	}
+	for blockName, v := range inputVars {
+		varData, ok := v.(data.VarData)
+		if !ok {
+			return nil, fmt.Errorf("unexpected input data for %s, got: %s", blockName, reflect.TypeOf(v))
+		}
+		varStore.Update(store.ToKey(blockName), varData)
+	}
+	refs := varStore.ListKeys()
+
+	celRenderer := celrenderer.New(varStore, map[string]any{})
+	var errs error
+	renderer := render2.New(func(ctx context.Context, expr string) (any, error) {
+		if !slices.ContainsFunc(refs, func(ref string) bool { return strings.Contains(expr, ref) }) {
+			return expr, nil
+		}
+		v, err := celRenderer.RenderString(ctx, expr)
+		if err == nil && v == nil {
+			err = fmt.Errorf("cannot render %q", expr)
+		}
+		if err != nil {
+			errs = errors.Join(errs, err)
+			return nil, err
+		}
+		return v, nil
+	})
+	n, err := renderer.Render(ctx, rn.Copy().YNode())
+	if err != nil {
+		return nil, err
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return yaml.NewRNode(n), nil
+}
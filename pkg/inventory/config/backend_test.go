@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"k8s.io/utils/ptr"
+)
+
+const input = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster
+  annotations:
+    kform.dev/block-type: input
+    kform.dev/default: "true"
+data:
+  host: https://10.0.0.1:6443
+`
+
+const backend = `apiVersion: backend.kform.dev/v1alpha1
+kind: Config
+metadata:
+  name: backend
+  annotations:
+    kform.dev/block-type: backend
+spec:
+  type: secret
+  host: input.cluster[0].data.host
+  tlsServerName: kube-apiserver
+  clusterCACertificate: "-----BEGIN CERTIFICATE-----"
+`
+
+func TestGetBackendConfig(t *testing.T) {
+	cases := map[string]struct {
+		files        map[string]string
+		inputVars    map[string]any
+		expectedType backendv1alpha1.BackendType
+		expectedHost *string
+	}{
+		"NoBackend": {
+			files:        map[string]string{"input.yaml": input},
+			expectedType: backendv1alpha1.BackendTypeConfigMap,
+		},
+		"InputDefault": {
+			files:        map[string]string{"input.yaml": input, "backend.yaml": backend},
+			expectedType: backendv1alpha1.BackendTypeSecret,
+			expectedHost: ptr.To("https://10.0.0.1:6443"),
+		},
+		"Input": {
+			files: map[string]string{"input.yaml": input, "backend.yaml": backend},
+			inputVars: map[string]any{
+				"input.cluster": data.VarData{data.DummyKey: []any{
+					map[string]any{"data": map[string]any{"host": "https://10.0.0.2:6443"}},
+				}},
+			},
+			expectedType: backendv1alpha1.BackendTypeSecret,
+			expectedHost: ptr.To("https://10.0.0.2:6443"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			pkgData := memory.NewStore[[]byte](nil)
+			for fileName, s := range tc.files {
+				pkgData.Create(store.ToKey(fileName), []byte(s))
+			}
+			backendConfig, err := GetBackendConfig(ctx, "", pkgData, tc.inputVars)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if backendConfig.GetType() != tc.expectedType {
+				t.Errorf("want type %s, got: %s", tc.expectedType, backendConfig.GetType())
+			}
+			if backendConfig.HasClusterConfig() != (tc.expectedHost != nil) {
+				t.Fatalf("want cluster config %t, got: %t", tc.expectedHost != nil, backendConfig.HasClusterConfig())
+			}
+			if tc.expectedHost == nil {
+				return
+			}
+			if ptr.Deref(backendConfig.Spec.Host, "") != *tc.expectedHost {
+				t.Errorf("want host %s, got: %s", *tc.expectedHost, ptr.Deref(backendConfig.Spec.Host, ""))
+			}
+			// literal values are not rendered
+			if ptr.Deref(backendConfig.Spec.ClusterCACertificate, "") != "-----BEGIN CERTIFICATE-----" {
+				t.Errorf("want literal certificate, got: %s", ptr.Deref(backendConfig.Spec.ClusterCACertificate, ""))
+			}
+		})
+	}
+}
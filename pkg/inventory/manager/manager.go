@@ -3,8 +3,14 @@ package manager
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/user"
+	"sync"
+	"time"
 
+	"github.com/henderiw/logger/log"
 	"github.com/henderiw/store"
+	backendv1alpha1 "github.com/kform-dev/kform/apis/backend/v1alpha1"
 	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
 	"github.com/kform-dev/kform/pkg/inventory/client"
@@ -15,54 +21,174 @@ import (
 
 type Manager interface {
 	GetInventory(ctx context.Context) (*invv1alpha1.Inventory, error)
-	Apply(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]]) error
+	// Apply stores the providers and the actuated resources with the info of their blocks
+	// and their status in the inventory
+	Apply(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*invv1alpha1.BlockInfo, statusFn invv1alpha1.ObjectStatusFn) error
 	Delete(ctx context.Context) error
+	// Unlock releases the lock of the inventory, which New acquired
+	Unlock(ctx context.Context) error
 	// AddProvider
 	// AddPackage
 	// AddResource
 	// ActuateInventory
 }
 
-func New(ctx context.Context, inv *unstructured.Unstructured, f util.Factory, strategy invv1alpha1.ActuationStrategy) (Manager, error) {
-	// create a client to interact with the cluster backend
-	client, err := client.ClusterClientFactory{StatusPolicy: policy.StatusPolicyNone}.NewClient(f)
+const (
+	// lockDuration is the time after which the lock of a run that stopped renewing it expires
+	lockDuration = 2 * time.Minute
+)
+
+// lockRenewInterval is the interval in which a run renews its lock
+var lockRenewInterval = 30 * time.Second
+
+// New returns a manager for the inventory stored in the backend of the backend config, w/o config
+// the inventory is stored in a ConfigMap. When lock is set the manager locks the inventory such
+// that concurrent runs on the same inventory are refused. The lock is renewed till Unlock is called.
+func New(ctx context.Context, inv *unstructured.Unstructured, f util.Factory, backendConfig *backendv1alpha1.Config, strategy invv1alpha1.ActuationStrategy, lock bool) (Manager, error) {
+	// create a backend to store the inventory
+	backend, err := client.ConfigBackendFactory{StatusPolicy: policy.StatusPolicyNone, Config: backendConfig}.NewBackend(f)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &manager{
-		client:         client,
+		backend:        backend,
 		localInventory: inv,
 		strategy:       strategy,
 	}
+	if lock {
+		if err := r.lock(ctx); err != nil {
+			return nil, err
+		}
+	}
 	return r, nil
 }
 
+// ForceUnlock releases the lock of the inventory irrespective of the run that holds it
+func ForceUnlock(ctx context.Context, inv *unstructured.Unstructured, f util.Factory, backendConfig *backendv1alpha1.Config) error {
+	backend, err := client.ConfigBackendFactory{StatusPolicy: policy.StatusPolicyNone, Config: backendConfig}.NewBackend(f)
+	if err != nil {
+		return err
+	}
+	return backend.Unlock(ctx, client.WrapInventoryInfoObj(inv), "")
+}
+
 type manager struct {
-	client         client.Client
+	backend        client.Backend
 	localInventory *unstructured.Unstructured
 	strategy       invv1alpha1.ActuationStrategy
+	// holder identifies the run holding the lock, empty when the inventory is not locked
+	holder string
+	// stopRenew stops the renewal of the lock
+	stopRenew context.CancelFunc
+	// m protects lockErr, which records why the run lost the lock
+	m       sync.Mutex
+	lockErr error
 }
 
-func (r *manager) Apply(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]]) error {
+func (r *manager) lock(ctx context.Context) error {
+	holder := getHolder()
+	invInfo := client.WrapInventoryInfoObj(r.localInventory)
+	if err := r.backend.Lock(ctx, invInfo, holder, lockDuration); err != nil {
+		return err
+	}
+	r.holder = holder
+	// the lock is renewed independent of the run context, since the lock is released
+	// after the run completes
+	renewCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	r.stopRenew = cancel
+	renewInterval := lockRenewInterval
+	go func() {
+		log := log.FromContext(renewCtx)
+		ticker := time.NewTicker(renewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				if err := r.backend.Lock(renewCtx, invInfo, holder, lockDuration); err != nil && renewCtx.Err() == nil {
+					// another run could take over the lock, so the inventory is no longer written
+					log.Error("cannot renew inventory lock", "err", err.Error())
+					r.m.Lock()
+					r.lockErr = err
+					r.m.Unlock()
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// checkLock returns an error when the run lost the lock of the inventory, the lock is
+// renewed to verify the run still holds it before the inventory is written
+func (r *manager) checkLock(ctx context.Context) error {
+	if r.holder == "" {
+		return nil
+	}
+	r.m.Lock()
+	lockErr := r.lockErr
+	r.m.Unlock()
+	if lockErr == nil {
+		lockErr = r.backend.Lock(ctx, client.WrapInventoryInfoObj(r.localInventory), r.holder, lockDuration)
+	}
+	if lockErr != nil {
+		return fmt.Errorf("lost the lock of the inventory, the inventory is not updated, err: %s", lockErr.Error())
+	}
+	return nil
+}
+
+func (r *manager) Unlock(ctx context.Context) error {
+	if r.holder == "" {
+		return nil
+	}
+	r.stopRenew()
+	if err := r.backend.Unlock(ctx, client.WrapInventoryInfoObj(r.localInventory), r.holder); err != nil {
+		return err
+	}
+	r.holder = ""
+	return nil
+}
+
+// getHolder identifies the run as <user>@<host>-<pid>
+func getHolder() string {
+	username := "unknown"
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s@%s-%d", username, hostname, os.Getpid())
+}
+
+func (r *manager) Apply(ctx context.Context, providers map[string]string, newActuatedResources store.Storer[store.Storer[data.BlockData]], blockInfos map[string]map[string]*invv1alpha1.BlockInfo, statusFn invv1alpha1.ObjectStatusFn) error {
 	// wrap the local inventory as a way to retrieve the inventory
 	invStore := client.WrapInventoryObj(r.localInventory)
-	inv, err := invStore.GetObject(ctx, providers, newActuatedResources)
+	inv, err := invStore.GetObject(ctx, providers, newActuatedResources, blockInfos, statusFn)
 	if err != nil {
 		return err
 	}
 	if inv == nil {
 		return fmt.Errorf("attempting to apply a nil inventory object")
 	}
-	return r.client.Apply(ctx, inv)
+	if err := r.checkLock(ctx); err != nil {
+		return err
+	}
+	return r.backend.Apply(ctx, inv)
 }
 
 func (r *manager) GetInventory(ctx context.Context) (*invv1alpha1.Inventory, error) {
 	invInfo := client.WrapInventoryInfoObj(r.localInventory)
-	// get the stored inventory from the clusterBackend
-	return r.client.GetClusterInventory(ctx, invInfo)
+	// get the stored inventory from the backend
+	return r.backend.GetClusterInventory(ctx, invInfo)
 }
 
 func (r *manager) Delete(ctx context.Context) error {
-	return r.client.Delete(ctx, r.localInventory)
+	if err := r.checkLock(ctx); err != nil {
+		return err
+	}
+	return r.backend.Delete(ctx, r.localInventory)
 }
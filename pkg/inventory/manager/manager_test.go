@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	invv1alpha1 "github.com/kform-dev/kform/apis/inv/v1alpha1"
+	"github.com/kform-dev/kform/pkg/data"
+	"github.com/kform-dev/kform/pkg/fsys"
+	"github.com/kform-dev/kform/pkg/inventory/client"
+	"github.com/kform-dev/kform/pkg/inventory/config"
+)
+
+func TestLostLock(t *testing.T) {
+	cases := map[string]struct {
+		// renew waits till the renewal of the lock failed
+		renew bool
+	}{
+		"Apply": {},
+		"Renew": {
+			renew: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			renewInterval := lockRenewInterval
+			defer func() { lockRenewInterval = renewInterval }()
+			lockRenewInterval = 10 * time.Millisecond
+
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "inventory.yaml")
+			backend := client.NewFileBackend(path)
+			invInfo := config.GetFakeInventoryInfo("test")
+			r := &manager{backend: backend, localInventory: invInfo, strategy: invv1alpha1.ActuationStrategyApply}
+			if err := r.lock(ctx); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer r.stopRenew()
+
+			// the lock is forced open and taken over by another run
+			if err := backend.Unlock(ctx, client.WrapInventoryInfoObj(invInfo), ""); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err := backend.Lock(ctx, client.WrapInventoryInfoObj(invInfo), "other", time.Minute); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.renew {
+				for i := 0; ; i++ {
+					r.m.Lock()
+					lockErr := r.lockErr
+					r.m.Unlock()
+					if lockErr != nil {
+						break
+					}
+					if i == 100 {
+						t.Fatalf("want renewal of the lock to fail")
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+
+			resources := memory.NewStore[store.Storer[data.BlockData]](nil)
+			if err := r.Apply(ctx, nil, resources, nil, nil); err == nil {
+				t.Errorf("want apply error, got nil")
+			}
+			if fsys.FileExists(path) {
+				t.Errorf("want inventory not to be written")
+			}
+			if err := r.Delete(ctx); err == nil {
+				t.Errorf("want delete error, got nil")
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 type KformWriter struct {
@@ -23,6 +24,8 @@ type KformWriter struct {
 	Path string
 
 	OuputData store.Storer[[]byte] // used for memory
+	// ShowSensitive reveals the values of sensitive outputs
+	ShowSensitive bool
 }
 
 func (r *KformWriter) Write(ctx context.Context, datastore store.Storer[data.BlockData]) error {
@@ -102,11 +105,7 @@ func (r *KformWriter) writeMem(keys []store.Key, datastore store.Storer[data.Blo
 		}
 
 		for _, rn := range bd {
-			rnAnnotations := rn.GetAnnotations()
-			for _, a := range kformv1alpha1.KformAnnotations {
-				delete(rnAnnotations, a)
-			}
-			rn.SetAnnotations(rnAnnotations)
+			r.prepare(rn)
 
 			fileName := filepath.Join(r.Path, fmt.Sprintf(
 				"%s.%s.%s.%s.yaml",
@@ -132,11 +131,7 @@ func (r *KformWriter) writeDir(keys []store.Key, datastore store.Storer[data.Blo
 		}
 
 		for _, rn := range bd {
-			rnAnnotations := rn.GetAnnotations()
-			for _, a := range kformv1alpha1.KformAnnotations {
-				delete(rnAnnotations, a)
-			}
-			rn.SetAnnotations(rnAnnotations)
+			r.prepare(rn)
 
 			file, err := os.Create(filepath.Join(r.Path, fmt.Sprintf(
 				"%s.%s.%s.%s.yaml",
@@ -165,11 +160,7 @@ func (r *KformWriter) writeSingle(w io.Writer, prioritizedKeys, keys []store.Key
 			continue
 		}
 		for _, rn := range bd {
-			rnAnnotations := rn.GetAnnotations()
-			for _, a := range kformv1alpha1.KformAnnotations {
-				delete(rnAnnotations, a)
-			}
-			rn.SetAnnotations(rnAnnotations)
+			r.prepare(rn)
 			fmt.Fprintf(w, "---\n%s\n", rn.MustString())
 		}
 	}
@@ -180,11 +171,7 @@ func (r *KformWriter) writeSingle(w io.Writer, prioritizedKeys, keys []store.Key
 			continue
 		}
 		for _, rn := range bd {
-			rnAnnotations := rn.GetAnnotations()
-			for _, a := range kformv1alpha1.KformAnnotations {
-				delete(rnAnnotations, a)
-			}
-			rn.SetAnnotations(rnAnnotations)
+			r.prepare(rn)
 			fmt.Fprintf(w, "---\n%s\n", rn.MustString())
 		}
 	}
@@ -208,14 +195,51 @@ func (r *KformWriter) writeRetainFile(files map[string][]store.Key, datastore st
 			}
 
 			for _, rn := range bd {
-				rnAnnotations := rn.GetAnnotations()
-				for _, a := range kformv1alpha1.KformAnnotations {
-					delete(rnAnnotations, a)
-				}
-				rn.SetAnnotations(rnAnnotations)
+				r.prepare(rn)
 				fmt.Fprintf(file, "---\n%s\n", rn.MustString())
 			}
 		}
 	}
 	return errm
 }
+
+// prepare removes the kform annotations from the output and masks the values
+// of sensitive outputs, unless ShowSensitive is set
+func (r *KformWriter) prepare(rn *yaml.RNode) {
+	rnAnnotations := rn.GetAnnotations()
+	sensitive := rnAnnotations[kformv1alpha1.KformAnnotationKey_SENSITIVE] == "true"
+	for _, a := range kformv1alpha1.KformAnnotations {
+		delete(rnAnnotations, a)
+	}
+	rn.SetAnnotations(rnAnnotations)
+	if sensitive && !r.ShowSensitive {
+		maskNode("", rn.YNode())
+	}
+}
+
+// maskNode replaces all scalar values with the sensitive mask, except
+// the fields that identify the object
+func maskNode(path string, n *yaml.Node) {
+	switch path {
+	case "apiVersion", "kind", "metadata.name", "metadata.namespace":
+		return
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			fieldPath := n.Content[i].Value
+			if path != "" {
+				fieldPath = path + "." + fieldPath
+			}
+			maskNode(fieldPath, n.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			maskNode(path+"[]", c)
+		}
+	case yaml.ScalarNode:
+		n.Value = kformv1alpha1.SensitiveMask
+		n.Tag = yaml.NodeTagString
+		n.Style = 0
+	}
+}
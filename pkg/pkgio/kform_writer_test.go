@@ -0,0 +1,78 @@
+package pkgio
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	"github.com/kform-dev/kform/pkg/data"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestKformWriterSensitive(t *testing.T) {
+	output := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+  namespace: default
+  annotations:
+    kform.dev/sensitive: "true"
+data:
+  password: secret
+  ports:
+  - 80
+`
+	cases := map[string]struct {
+		showSensitive  bool
+		expectedOutput []string
+		unexpected     []string
+	}{
+		"Masked": {
+			expectedOutput: []string{"name: cm1", "namespace: default", "password: '***'", "- '***'"},
+			unexpected:     []string{"secret", "80", "kform.dev/sensitive"},
+		},
+		"ShowSensitive": {
+			showSensitive:  true,
+			expectedOutput: []string{"password: secret", "- 80"},
+			unexpected:     []string{"***", "kform.dev/sensitive"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			rn, err := yaml.Parse(output)
+			if err != nil {
+				t.Fatalf("cannot parse output: %s", err)
+			}
+			datastore := memory.NewStore[data.BlockData](nil)
+			datastore.Create(store.ToKey("output.cm1"), data.BlockData{rn})
+
+			outputData := memory.NewStore[[]byte](nil)
+			w := KformWriter{
+				Type:          OutputSink_Memory,
+				OuputData:     outputData,
+				ShowSensitive: tc.showSensitive,
+			}
+			if err := w.Write(ctx, datastore); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var sb strings.Builder
+			outputData.List(func(k store.Key, b []byte) {
+				sb.Write(b)
+			})
+			for _, s := range tc.expectedOutput {
+				if !strings.Contains(sb.String(), s) {
+					t.Errorf("want output to contain %q, got:\n%s", s, sb.String())
+				}
+			}
+			for _, s := range tc.unexpected {
+				if strings.Contains(sb.String(), s) {
+					t.Errorf("want output not to contain %q, got:\n%s", s, sb.String())
+				}
+			}
+		})
+	}
+}
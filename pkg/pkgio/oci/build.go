@@ -99,8 +99,13 @@ func TgzReader(ctx context.Context, r io.Reader, data store.Storer[[]byte]) erro
 	}
 	defer gzipReader.Close()
 
+	return TarReader(ctx, gzipReader, data)
+}
+
+// TarReader reads the files of the tar archive in the data store
+func TarReader(ctx context.Context, r io.Reader, data store.Storer[[]byte]) error {
 	// Create a tar reader
-	tarReader := tar.NewReader(gzipReader)
+	tarReader := tar.NewReader(r)
 
 	// Iterate through the contents of the tar file
 	for {
@@ -117,7 +122,6 @@ func TgzReader(ctx context.Context, r io.Reader, data store.Storer[[]byte]) erro
 		// Print the name of the file or directory
 		switch header.Typeflag {
 		case tar.TypeDir:
-			fmt.Println("dir:", header.Name)
 			/*
 				if err := os.Mkdir(header.Name, 0755); err != nil {
 					log.Fatalf("ExtractTarGz: Mkdir() failed: %s", err.Error())
@@ -125,7 +129,6 @@ func TgzReader(ctx context.Context, r io.Reader, data store.Storer[[]byte]) erro
 			*/
 		case tar.TypeReg:
 			// Create a buffer to hold the file content in memory
-			fmt.Println("Extracting:", header.Name)
 			fileContent := new(bytes.Buffer)
 			/*
 				if err != nil {
@@ -181,7 +184,6 @@ func ReadTgz(tgzData []byte) ([]byte, error) {
 		// Print the name of the file or directory
 		switch header.Typeflag {
 		case tar.TypeDir:
-			fmt.Println("dir:", header.Name)
 			/*
 				if err := os.Mkdir(header.Name, 0755); err != nil {
 					log.Fatalf("ExtractTarGz: Mkdir() failed: %s", err.Error())
@@ -189,7 +191,6 @@ func ReadTgz(tgzData []byte) ([]byte, error) {
 			*/
 		case tar.TypeReg:
 			// Create a buffer to hold the file content in memory
-			fmt.Println("Extracting:", header.Name)
 			fileContent := new(bytes.Buffer)
 			/*
 				if err != nil {
@@ -254,11 +255,9 @@ func UnzipTgz(ctx context.Context, basepath string, tgzData []byte, data store.S
 		}
 		switch header.Typeflag {
 		case tar.TypeDir:
-			fmt.Println("dir:", header.Name)
 			// TBD need to make dir?
 		case tar.TypeReg:
 			// Create a buffer to hold the file content in memory
-			fmt.Println("Extracting:", header.Name)
 			fileContent := new(bytes.Buffer)
 			/*
 				if err != nil {
@@ -4,7 +4,6 @@ import (
 	"compress/gzip"
 	"errors"
 	"io"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"sync"
@@ -25,10 +24,11 @@ type Cache interface {
 }
 
 // fsCache stores and retrieves package content in a filesystem-backed
-// cache in a thread-safe manner.
+// cache in a thread-safe manner. Content is stored through a temporary file
+// that is renamed in place, such that concurrent processes sharing the cache
+// never observe partially written content.
 type fsCache struct {
 	dir string
-	fs  fs.FS
 	m   sync.RWMutex
 }
 
@@ -36,13 +36,16 @@ type fsCache struct {
 func NewFsCache(dir string) Cache {
 	return &fsCache{
 		dir: dir,
-		fs:  os.DirFS(dir),
 	}
 }
 
+func (r *fsCache) path(id string) string {
+	return filepath.Join(r.dir, id+cacheContentExt)
+}
+
 // Has indicates whether an item with the given id is in the cache.
 func (r *fsCache) Has(id string) bool {
-	if fi, err := os.Stat(filepath.Join(r.dir, id, cacheContentExt)); err == nil && !fi.IsDir() {
+	if fi, err := os.Stat(r.path(id)); err == nil && !fi.IsDir() {
 		return true
 	}
 	return false
@@ -52,22 +55,28 @@ func (r *fsCache) Has(id string) bool {
 func (r *fsCache) Get(id string) (io.ReadCloser, error) {
 	r.m.RLock()
 	defer r.m.RUnlock()
-	f, err := r.fs.Open(filepath.Join(r.dir, id, cacheContentExt))
+	f, err := os.Open(r.path(id))
 	if err != nil {
 		return nil, err
 	}
 	return GzipReadCloser(f)
 }
 
-// Get retrieves package contents from the cache.
+// Store saves package contents to the cache.
 func (r *fsCache) Store(id string, content io.ReadCloser) error {
 	r.m.Lock()
 	defer r.m.Unlock()
-	cf, err := os.Create(filepath.Join(r.dir, id, cacheContentExt))
+	defer content.Close() //nolint:errcheck // nothing to do when closing the content fails
+	path := r.path(id)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	cf, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
 	if err != nil {
 		return err
 	}
-	defer cf.Close() //nolint:errcheck // Error is checked in the happy path.
+	defer os.Remove(cf.Name()) //nolint:errcheck // the temporary file is gone after the rename
+	defer cf.Close()           //nolint:errcheck // Error is checked in the happy path.
 	w, err := gzip.NewWriterLevel(cf, gzip.BestSpeed)
 	if err != nil {
 		return err
@@ -80,14 +89,17 @@ func (r *fsCache) Store(id string, content io.ReadCloser) error {
 	if err := w.Close(); err != nil {
 		return err
 	}
-	return cf.Close()
+	if err := cf.Close(); err != nil {
+		return err
+	}
+	return os.Rename(cf.Name(), path)
 }
 
-// Get retrieves package contents from the cache.
+// Delete removes package contents from the cache.
 func (r *fsCache) Delete(id string) error {
 	r.m.Lock()
 	defer r.m.Unlock()
-	err := os.Remove(filepath.Join(r.dir, id, cacheContentExt))
+	err := os.Remove(r.path(id))
 	if os.IsNotExist(err) {
 		return nil
 	}
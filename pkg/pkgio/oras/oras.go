@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/henderiw/logger/log"
 	"github.com/kform-dev/kform/pkg/pkgio/oci"
@@ -18,6 +18,7 @@ import (
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/memory"
+	ocilayout "oras.land/oras-go/v2/content/oci"
 
 	//"oras.land/oras-go/v2/oras"
 	"github.com/henderiw/store"
@@ -56,20 +57,36 @@ type Tags []string
 
 func GetTags(ctx context.Context, ref string) (Tags, error) {
 	tags := Tags{}
-	fmt.Println("ref", ref)
 	target, err := GetRepository(ctx, ref)
 	if err != nil {
 		return tags, errors.Wrap(err, "cannot get repository")
 	}
-	fmt.Println("got target", target)
-	target.Tags(ctx, "", func(t []string) error {
-		fmt.Println("got tags", t)
+	if err := target.Tags(ctx, "", func(t []string) error {
 		tags = append(tags, t...)
 		return nil
-	})
+	}); err != nil {
+		return tags, errors.Wrapf(err, "cannot list tags of %s", ref)
+	}
 	return tags, nil
 }
 
+// Resolve returns the digest of the manifest the reference points to
+func Resolve(ctx context.Context, ref string) (string, error) {
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse reference")
+	}
+	target, err := GetRepository(ctx, ref)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot get repository")
+	}
+	desc, err := target.Resolve(ctx, parsedRef.Reference)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot resolve %s", ref)
+	}
+	return desc.Digest.String(), nil
+}
+
 func GetRepository(ctx context.Context, ref string) (registry.Repository, error) {
 	parsedRef, err := registry.ParseReference(ref)
 	if err != nil {
@@ -189,6 +206,81 @@ func Pull(ctx context.Context, ref string, data store.Storer[[]byte]) error {
 	return nil
 }
 
+// GetLayoutTags returns the tags of the OCI image layout in the dir
+func GetLayoutTags(ctx context.Context, dir string) (Tags, error) {
+	tags := Tags{}
+	src, err := ocilayout.NewFromFS(ctx, os.DirFS(dir))
+	if err != nil {
+		return tags, errors.Wrapf(err, "cannot open oci layout %s", dir)
+	}
+	if err := src.Tags(ctx, "", func(t []string) error {
+		tags = append(tags, t...)
+		return nil
+	}); err != nil {
+		return tags, errors.Wrapf(err, "cannot list tags of %s", dir)
+	}
+	return tags, nil
+}
+
+// ResolveLayout returns the digest of the manifest the reference (tag or digest) points to
+// in the OCI image layout in the dir
+func ResolveLayout(ctx context.Context, dir, reference string) (string, error) {
+	src, err := ocilayout.NewFromFS(ctx, os.DirFS(dir))
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot open oci layout %s", dir)
+	}
+	desc, err := src.Resolve(ctx, reference)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot resolve %s in %s", reference, dir)
+	}
+	return desc.Digest.String(), nil
+}
+
+// PullFromLayout pulls the package the reference (tag or digest) points to from the
+// OCI image layout in the dir
+func PullFromLayout(ctx context.Context, dir, reference string, data store.Storer[[]byte]) error {
+	log := log.FromContext(ctx).With("dir", dir, "reference", reference)
+	log.Info("pulling package from oci layout")
+	// dst -> memory
+	dst := memory.New()
+	// src -> oci layout
+	src, err := ocilayout.NewFromFS(ctx, os.DirFS(dir))
+	if err != nil {
+		return errors.Wrapf(err, "cannot open oci layout %s", dir)
+	}
+	desc, err := oras.Copy(ctx, src, reference, dst, "", oras.DefaultCopyOptions)
+	if err != nil {
+		return errors.Wrap(err, "cannot copy")
+	}
+	if err := mem2file(ctx, reference, dst, desc, data); err != nil {
+		return errors.Wrap(err, "cannot copy memrfile")
+	}
+	log.Info("pulled package successfully", "digest", desc.Digest)
+	return nil
+}
+
+// CopyToLayout copies the package the ref points to from the registry to the OCI image layout
+// in the dir and tags it with the tag. It returns the digest of the copied manifest.
+func CopyToLayout(ctx context.Context, ref, dir, tag string) (string, error) {
+	log := log.FromContext(ctx).With("ref", ref, "dir", dir)
+	// src -> registry
+	src, err := GetRepository(ctx, ref)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot get remote repo")
+	}
+	// dst -> oci layout
+	dst, err := ocilayout.New(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot create oci layout %s", dir)
+	}
+	desc, err := oras.Copy(ctx, src, ref, dst, tag, oras.DefaultCopyOptions)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot copy")
+	}
+	log.Info("copied package successfully", "digest", desc.Digest)
+	return desc.Digest.String(), nil
+}
+
 func mem2file(ctx context.Context, ref string, dst *memory.Store, desc ocispecv1.Descriptor, data store.Storer[[]byte]) error {
 	log := log.FromContext(ctx).With("ref", ref)
 	rc, err := dst.Fetch(ctx, desc)
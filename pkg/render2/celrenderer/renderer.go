@@ -27,6 +27,7 @@ import (
 	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
 	"github.com/kform-dev/kform/pkg/data"
 	"github.com/kform-dev/kform/pkg/render2"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 type CelRenderer interface {
@@ -125,7 +126,8 @@ func (r *renderer) RenderString(ctx context.Context, expr string) (any, error) {
 			newVars[newVar] = v
 		}
 		log.Debug("expression", "expr", expr)
-		log.Debug("expression", "vars", newVars)
+		// only the names of the variables are logged as their values could be sensitive
+		log.Debug("expression", "vars", sets.List(sets.KeySet(newVars)))
 		env, err := getCelEnv(newVars)
 		if err != nil {
 			log.Error("cel environment failed", "error", err)
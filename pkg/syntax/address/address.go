@@ -72,6 +72,11 @@ func (r *Package) Filename() string {
 	return fmt.Sprintf("%s_%s", r.Address.Name, r.Platform.String())
 }
 
+// ArchiveFilename returns the name of the release archive of the version, aligned with go releaser
+func (r *Package) ArchiveFilename(version string) string {
+	return fmt.Sprintf("%s_%s_%s.tar.gz", r.Address.Name, version, r.Platform.String())
+}
+
 func (r *Package) githubChecksumPath(version string) string {
 	return filepath.Join(r.Address.Namespace, "releases", "download", fmt.Sprintf("v%s", version), r.checksumFilename())
 }
@@ -137,7 +142,12 @@ func (r *Package) BasePath() string {
 }
 
 func (r *Package) ExecPath() string {
-	return filepath.Join(r.Address.Path(), r.SelectedVersion, r.Platform.String(), "image", r.Address.Name)
+	return r.ExecPathWithVersion(r.SelectedVersion)
+}
+
+// ExecPathWithVersion returns the path of the binary of the version of the package
+func (r *Package) ExecPathWithVersion(version string) string {
+	return filepath.Join(r.Address.Path(), version, r.Platform.String(), "image", r.Address.Name)
 }
 
 func (r *Package) FilePath(version string) string {
@@ -127,18 +127,61 @@ func (r *Package) AddConstraints(constraint string) {
 	} else {
 		r.VersionConstraints = fmt.Sprintf("%s, %s", r.VersionConstraints, constraint)
 	}
-	fmt.Println("constraints", r.VersionConstraints)
+}
+
+// AddAvailableVersions adds the versions of the tags (v<version>) to the available versions,
+// tags that are not a version are ignored
+func (r *Package) AddAvailableVersions(tags []string) {
+	for _, tag := range tags {
+		v, err := versions.ParseVersion(strings.TrimPrefix(tag, "v"))
+		if err != nil {
+			continue
+		}
+		r.AvailableVersions = append(r.AvailableVersions, v)
+	}
 }
 
 func (r *Package) GenerateCandidates() error {
+	if r.VersionConstraints == "" {
+		// w/o constraints all versions are candidates
+		r.CandidateVersions = r.AvailableVersions
+		return nil
+	}
 	allowed, err := versions.MeetingConstraintsStringRuby(r.VersionConstraints)
 	if err != nil {
 		return errors.Wrap(err, "invalid version constraint")
 	}
-	fmt.Println("allowed versions", allowed)
-	fmt.Println("available versions", r.AvailableVersions)
 	r.CandidateVersions = r.AvailableVersions.Filter(allowed)
-	fmt.Println("candidate versions", r.CandidateVersions)
+	return nil
+}
+
+// SelectNewest selects the newest available version that meets the version constraints
+func (r *Package) SelectNewest() error {
+	if err := r.GenerateCandidates(); err != nil {
+		return err
+	}
+	if len(r.CandidateVersions) == 0 {
+		return fmt.Errorf("no version of %s meets the constraints %q, available versions: %v",
+			r.Address.Path(), r.VersionConstraints, r.AvailableVersions)
+	}
+	r.SelectedVersion = r.CandidateVersions.Newest().String()
+	return nil
+}
+
+// SelectVersion selects the version, which must be available and meet the version constraints
+func (r *Package) SelectVersion(version string) error {
+	if err := r.GenerateCandidates(); err != nil {
+		return err
+	}
+	v, err := versions.ParseVersion(version)
+	if err != nil {
+		return errors.Wrapf(err, "invalid version %s", version)
+	}
+	if !r.CandidateVersions.Set().Has(v) {
+		return fmt.Errorf("version %s of %s is not available or does not meet the constraints %q",
+			version, r.Address.Path(), r.VersionConstraints)
+	}
+	r.SelectedVersion = v.String()
 	return nil
 }
 
@@ -170,7 +213,6 @@ func (r *Package) HasVersion(version string) bool {
 }
 
 func (r *Package) Newest() string {
-	fmt.Println("candidiate versions", r.CandidateVersions)
 	return r.CandidateVersions.Newest().String()
 }
 
@@ -0,0 +1,57 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/henderiw/store"
+)
+
+func TestSelectNewest(t *testing.T) {
+	cases := map[string]struct {
+		tags            []string
+		constraints     []string
+		expectedVersion string
+		expectedErr     bool
+	}{
+		"NoConstraints": {
+			tags:            []string{"v0.0.1", "v0.1.0", "v0.0.2", "latest"},
+			expectedVersion: "0.1.0",
+		},
+		"Constraints": {
+			tags:            []string{"v0.0.1", "v0.1.0", "v0.0.2"},
+			constraints:     []string{">= 0.0.1", "< 0.1.0"},
+			expectedVersion: "0.0.2",
+		},
+		"NoCandidates": {
+			tags:        []string{"v0.0.1"},
+			constraints: []string{">= 0.1.0"},
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg, err := GetPackage(store.ToKey("kubernetes"), "europe-docker.pkg.dev/srlinux/eu.gcr.io")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for _, constraint := range tc.constraints {
+				pkg.AddConstraints(constraint)
+			}
+			pkg.AddAvailableVersions(tc.tags)
+			err = pkg.SelectNewest()
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if pkg.SelectedVersion != tc.expectedVersion {
+				t.Errorf("want version %s, got: %s", tc.expectedVersion, pkg.SelectedVersion)
+			}
+		})
+	}
+}
@@ -32,22 +32,31 @@ func (r *Release) GetImageData(ctx context.Context) (Images, error) {
 	for _, asset := range r.Assets {
 		log.Info("asset info", "name", asset.Name, "contentType", asset.ContentType, "state", asset.State)
 		if asset.ContentType == "application/gzip" && asset.State == "uploaded" {
-			rawAssetName := strings.TrimSuffix(asset.Name, ".tar.gz")
-			split := strings.Split(rawAssetName, "_")
-			if len(split) != 4 {
-				log.Error("wrong release name: expecting <name>_<version>_<os>_<arch>", "got", rawAssetName)
-				return images, fmt.Errorf("wrong release name: expecting <name>_<os>_<arch>, got: %s", rawAssetName)
+			image, err := ParseImageName(asset.Name)
+			if err != nil {
+				log.Error("wrong release name: expecting <name>_<version>_<os>_<arch>", "got", asset.Name)
+				return images, err
 			}
-			images = append(images, Image{
-				Name:    asset.Name,
-				Version: split[1],
-				Platform: Platform{
-					OS:   split[2],
-					Arch: split[3],
-				},
-				URL: asset.BrowserDownloadURL,
-			})
+			image.URL = asset.BrowserDownloadURL
+			images = append(images, *image)
 		}
 	}
 	return images, nil
 }
+
+// ParseImageName parses the name of a release archive <name>_<version>_<os>_<arch>.tar.gz
+func ParseImageName(name string) (*Image, error) {
+	rawAssetName := strings.TrimSuffix(name, ".tar.gz")
+	split := strings.Split(rawAssetName, "_")
+	if len(split) != 4 {
+		return nil, fmt.Errorf("wrong release name: expecting <name>_<version>_<os>_<arch>, got: %s", rawAssetName)
+	}
+	return &Image{
+		Name:    name,
+		Version: split[1],
+		Platform: Platform{
+			OS:   split[2],
+			Arch: split[3],
+		},
+	}, nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/henderiw/store"
+	"github.com/kform-dev/kform/pkg/pkgio/oci"
+	"github.com/kform-dev/kform/pkg/syntax/address"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// pluginCacheDirEnv is the env variable that locates the plugin cache shared by all packages
+const pluginCacheDirEnv = "KFORM_PLUGIN_CACHE_DIR"
+
+// GetPluginCacheDir returns the directory of the shared plugin cache, an empty string
+// when no plugin cache is used
+func GetPluginCacheDir() string {
+	return os.Getenv(pluginCacheDirEnv)
+}
+
+func getPluginCache(dir string) oci.Cache {
+	if dir == "" {
+		return oci.NewNopCache()
+	}
+	return oci.NewFsCache(dir)
+}
+
+// getPluginCacheID returns the id of a provider release in the plugin cache, which is keyed by
+// <hostname>/<namespace>/<name>/<version>/<os>_<arch>/<manifest-digest>
+func getPluginCacheID(pkg *address.Package, version, platform, digest string) string {
+	return filepath.Join(pkg.Address.Path(), version, platform, strings.ReplaceAll(digest, ":", "-"))
+}
+
+// storeProviderPackage stores the files of the provider package as a tar archive in the cache
+func storeProviderPackage(cache oci.Cache, id string, pkgData store.Storer[[]byte]) error {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		var errm error
+		pkgData.List(func(k store.Key, b []byte) {
+			if errm != nil {
+				return
+			}
+			if err := tw.WriteHeader(&tar.Header{Name: k.Name, Mode: 0644, Size: int64(len(b)), Typeflag: tar.TypeReg}); err != nil {
+				errm = err
+				return
+			}
+			_, errm = tw.Write(b)
+		})
+		if errm == nil {
+			errm = tw.Close()
+		}
+		pw.CloseWithError(errm)
+	}()
+	return cache.Store(id, pr)
+}
+
+// loadProviderPackage loads the files of the provider package from the cache
+func loadProviderPackage(ctx context.Context, cache oci.Cache, id string, pkgData store.Storer[[]byte]) error {
+	rc, err := cache.Get(id)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return oci.TarReader(ctx, rc, pkgData)
+}
+
+// PrunePluginCache deletes the provider releases from the plugin cache in dir that are not
+// locked by one of the lock files and returns the ids of the deleted releases
+func PrunePluginCache(dir string, lockFiles []string) ([]string, error) {
+	keep := sets.New[string]()
+	for _, lockFile := range lockFiles {
+		lock, err := ReadLockFile(lockFile)
+		if err != nil {
+			return nil, err
+		}
+		for providerName, providerLock := range lock.Spec.Providers {
+			pkg, err := address.GetPackage(store.ToKey(providerName), providerLock.Source)
+			if err != nil {
+				return nil, err
+			}
+			for platform, checksums := range providerLock.Platforms {
+				keep.Insert(getPluginCacheID(pkg, providerLock.Version, platform, checksums.Manifest))
+			}
+		}
+	}
+
+	cache := oci.NewFsCache(dir)
+	pruned := []string{}
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".gz") {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		id := strings.TrimSuffix(relPath, ".gz")
+		if keep.Has(id) {
+			return nil
+		}
+		if err := cache.Delete(id); err != nil {
+			return err
+		}
+		pruned = append(pruned, id)
+		return nil
+	}); err != nil {
+		return pruned, err
+	}
+	return pruned, removeEmptyDirs(dir)
+}
+
+// removeEmptyDirs removes the empty directories below dir
+func removeEmptyDirs(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if err := removeEmptyDirs(path); err != nil {
+			return err
+		}
+		if entries, err := os.ReadDir(path); err == nil && len(entries) == 0 {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	lockv1alpha1 "github.com/kform-dev/kform/apis/lock/v1alpha1"
+)
+
+func TestPluginCache(t *testing.T) {
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+	cache := getPluginCache(cacheDir)
+	pkg := getTestProviderPackage(t)
+
+	pkgData := memory.NewStore[[]byte](nil)
+	pkgData.Create(store.ToKey(filepath.Join(providerImageDir, pkg.Address.Name)), []byte("binary"))
+	pkgData.Create(store.ToKey("README.md"), []byte("readme"))
+
+	ids := map[string]string{}
+	for _, version := range []string{"0.0.1", "0.0.2"} {
+		ids[version] = getPluginCacheID(pkg, version, "linux_amd64", "sha256:"+version)
+		if err := storeProviderPackage(cache, ids[version], pkgData); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	got := memory.NewStore[[]byte](nil)
+	if err := loadProviderPackage(ctx, cache, ids["0.0.1"], got); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(listFiles(pkgData), listFiles(got)); diff != "" {
+		t.Errorf("-want files, +got:\n%s", diff)
+	}
+
+	// the releases locked by the lock files are retained
+	lock := lockv1alpha1.BuildLock()
+	lock.Spec.Providers[pkg.Address.Name] = &lockv1alpha1.ProviderLock{
+		Source:  "europe-docker.pkg.dev/srlinux/eu.gcr.io",
+		Version: "0.0.2",
+		Platforms: map[string]*lockv1alpha1.PlatformChecksums{
+			"linux_amd64": {Manifest: "sha256:0.0.2", Binary: "sha256:0"},
+		},
+	}
+	lockFile := GetLockFile(t.TempDir())
+	if err := writeLockFile(lockFile, lock); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pruned, err := PrunePluginCache(cacheDir, []string{lockFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff([]string{ids["0.0.1"]}, pruned); diff != "" {
+		t.Errorf("-want pruned, +got:\n%s", diff)
+	}
+	if cache.Has(ids["0.0.1"]) || !cache.Has(ids["0.0.2"]) {
+		t.Errorf("want only the locked release in the cache")
+	}
+
+	// w/o lock files all releases are pruned
+	if _, err := PrunePluginCache(cacheDir, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cache.Has(ids["0.0.2"]) {
+		t.Errorf("want empty cache")
+	}
+}
+
+func listFiles(s store.Storer[[]byte]) map[string]string {
+	files := map[string]string{}
+	s.List(func(k store.Key, b []byte) {
+		files[k.Name] = string(b)
+	})
+	return files
+}
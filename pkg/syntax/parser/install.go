@@ -18,69 +18,285 @@ package parser
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/henderiw/logger/log"
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	lockv1alpha1 "github.com/kform-dev/kform/apis/lock/v1alpha1"
+	"github.com/kform-dev/kform/pkg/fsys"
+	"github.com/kform-dev/kform/pkg/pkgio/oci"
+	"github.com/kform-dev/kform/pkg/recorder/diag"
+	"github.com/kform-dev/kform/pkg/syntax/address"
+)
+
+const (
+	// the providers of a package are installed in <PKG-DIR>/.kform/providers
+	kformDir          = ".kform"
+	kformProvidersDir = "providers"
+	// the binary of a provider package is located in the image directory
+	providerImageDir = "image"
 )
 
+// GetPluginDir returns the directory in which the providers of the package at path are installed
+func GetPluginDir(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(path, kformDir, kformProvidersDir)
+}
+
 // validateAndOrInstallProviders looks at the provider requirements
 // 1. convert provider requirements to packages
 // 2. get the releases per provider (based in source <hostname>/<namespace>)
+// 3. select the release, when installing the locked release or otherwise the newest
+// release meeting the version constraints is pulled from the registry or the filesystem
+// mirror into the plugin
+// dir and recorded in the lock file. Otherwise the locked release is selected from the
+// releases in the plugin dir and the checksum of its binary is verified.
+// providers w/o source are local and are located through the KFORM_PROVIDER_<NAME> env variable
 func (r *KformParser) validateAndOrInstallProviders(ctx context.Context) {
-	// TODO
-	/*
-		for providerName, providerReq := range r.listProviderRequirements(ctx) {
-				// convert provider requirements to a package
-				// the source was validated to be aligned before so we can just pick the first one.
-				pkg, err := address.GetPackage(providerName, providerReq[0].Source)
-				if err != nil {
-					r.recorder.Record(diag.DiagFromErr(err))
-					return
-				}
-				// retrieve the available releases/versions for this provider
-				if !pkg.IsLocal() {
-					tags, err := oras.GetTags(ctx, pkg.GetRef())
-					if err != nil {
-						return
-					}
-					for _, tag := range tags {
-						v, err := versions.ParseVersion(strings.ReplaceAll(tag, "v", ""))
-						if err != nil {
-							r.recorder.Record(diag.DiagFromErr(err))
-							continue
-						}
-						pkg.AvailableVersions = append(pkg.AvailableVersions, v)
-					}
-					// append the requirements together
-					for _, req := range reqs {
-						pkg.AddConstraints(req.Version)
-					}
-
-					// generate the candidate versions by looking at the available
-					// versions and applying the constraints on them
-					if err := pkg.GenerateCandidates(); err != nil {
-						r.recorder.Record(diag.DiagFromErr(err))
-						return
-					}
-				}
-				r.providers.Add(ctx, nsn, pkg)
-		}
-
-			pkgrw := pkgio.NewPkgProviderReadWriter(r.rootModulePath, r.providers)
-			pl := pkgio.Pipeline{
-				Inputs:     []pkgio.Reader{pkgrw},
-				Processors: []pkgio.Process{pkgrw},
-				Outputs:    []pkgio.Writer{pkgrw},
+	lock, err := ReadLockFile(r.cfg.LockFile)
+	if err != nil {
+		r.recorder.Record(diag.DiagFromErr(err))
+		return
+	}
+	newLock := lockv1alpha1.BuildLock()
+	for providerName, providerReqs := range r.listProviderRequirements(ctx) {
+		// the source was validated to be aligned before so we can just pick the first one.
+		source := ""
+		if len(providerReqs) > 0 {
+			source = providerReqs[0].Source
+		}
+		pkg, err := address.GetPackage(store.ToKey(providerName), source)
+		if err != nil {
+			r.recorder.Record(diag.DiagErrorf("provider %s, err: %s", providerName, err.Error()))
+			continue
+		}
+		r.providers[providerName] = pkg
+		if pkg.IsLocal() {
+			continue
+		}
+		for _, req := range providerReqs {
+			if req.Version != "" {
+				pkg.AddConstraints(req.Version)
 			}
-			if !init {
-				// when doing kform apply we just want to validate the provider pkg(s)
-				pl = pkgio.Pipeline{
-					Inputs:     []pkgio.Reader{pkgrw},
-					Processors: []pkgio.Process{pkgrw},
-					Outputs:    []pkgio.Writer{pkgio.NewPkgValidator()},
-				}
+		}
+		providerLock := lock.GetProvider(providerName)
+		if providerLock != nil && providerLock.Source != source {
+			// the lock of a provider that moved to another source is not applicable
+			providerLock = nil
+		}
+		if r.cfg.InstallProviders {
+			if r.cfg.UpgradeProviders {
+				providerLock = nil
 			}
+			providerLock, err = r.installProvider(ctx, pkg, source, providerLock)
+			if err == nil {
+				newLock.Spec.Providers[providerName] = providerLock
+			}
+		} else if _, found := os.LookupEnv(getProviderEnv(providerName)); !found {
+			err = r.selectInstalledProvider(ctx, pkg, providerLock)
+		}
+		if err != nil {
+			r.recorder.Record(diag.DiagErrorf("provider %s, err: %s", providerName, err.Error()))
+		}
+	}
+	if r.cfg.InstallProviders && r.cfg.LockFile != "" && !r.recorder.Get().HasError() {
+		if err := writeLockFile(r.cfg.LockFile, newLock); err != nil {
+			r.recorder.Record(diag.DiagFromErr(err))
+		}
+	}
+}
 
-			if err := pl.Execute(ctx); err != nil {
-				r.recorder.Record(diag.DiagFromErr(err))
-				return
+// installProvider pulls the release of the provider for the platform and unpacks it in the plugin dir.
+// The locked release is installed when it meets the version constraints, otherwise the newest release
+// meeting the version constraints. The returned lock records the checksums of the installed release.
+func (r *KformParser) installProvider(ctx context.Context, pkg *address.Package, source string, locked *lockv1alpha1.ProviderLock) (*lockv1alpha1.ProviderLock, error) {
+	log := log.FromContext(ctx).With("provider", pkg.GetRef())
+	if r.cfg.PluginDir == "" {
+		return nil, fmt.Errorf("cannot install provider w/o plugin directory")
+	}
+	src := r.getProviderSource(pkg)
+	tags, err := src.GetTags(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	pkg.AddAvailableVersions(tags)
+	if locked != nil {
+		if err := pkg.SelectVersion(locked.Version); err != nil {
+			return nil, fmt.Errorf("locked %s, run kform init --upgrade", err.Error())
+		}
+	} else if err := pkg.SelectNewest(); err != nil {
+		return nil, err
+	}
+	tag := getVersionTag(tags, pkg.SelectedVersion)
+	ref := fmt.Sprintf("%s:%s", pkg.GetRef(), tag)
+	digest, err := src.Resolve(ctx, pkg, tag)
+	if err != nil {
+		return nil, err
+	}
+	checksums := locked.GetPlatform(pkg.Platform.String())
+	// releases w/o manifest are only verified by the checksum of the binary
+	if checksums != nil && digest != "" && checksums.Manifest != digest {
+		return nil, fmt.Errorf("manifest digest of %s does not match the lock file, want: %s, got: %s", ref, checksums.Manifest, digest)
+	}
+
+	execPath := filepath.Join(r.cfg.PluginDir, pkg.ExecPath())
+	if fsys.FileExists(execPath) {
+		log.Debug("provider already installed", "version", pkg.SelectedVersion)
+	} else {
+		log.Info("installing provider", "version", pkg.SelectedVersion)
+		pkgData, err := src.Pull(ctx, pkg, tag, digest)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeProviderPackage(ctx, filepath.Join(r.cfg.PluginDir, pkg.FilePathWithSelectedVersion()), pkgData); err != nil {
+			return nil, err
+		}
+		if !fsys.FileExists(execPath) {
+			return nil, fmt.Errorf("provider package %s does not contain the binary %s", ref, filepath.Join(providerImageDir, pkg.Address.Name))
+		}
+	}
+	if checksums != nil {
+		if err := verifyBinaryChecksum(execPath, checksums); err != nil {
+			return nil, err
+		}
+	}
+	binaryChecksum, err := getBinaryChecksum(execPath)
+	if err != nil {
+		return nil, err
+	}
+
+	providerLock := &lockv1alpha1.ProviderLock{
+		Source:      source,
+		Version:     pkg.SelectedVersion,
+		Constraints: pkg.VersionConstraints,
+		Platforms:   map[string]*lockv1alpha1.PlatformChecksums{},
+	}
+	// the checksums of the other platforms are retained as long as the version is unchanged
+	if locked != nil && locked.Version == pkg.SelectedVersion {
+		for platform, checksums := range locked.Platforms {
+			providerLock.Platforms[platform] = checksums
+		}
+	}
+	if digest == "" && checksums != nil {
+		digest = checksums.Manifest
+	}
+	providerLock.Platforms[pkg.Platform.String()] = &lockv1alpha1.PlatformChecksums{
+		Manifest: digest,
+		Binary:   binaryChecksum,
+	}
+	return providerLock, nil
+}
+
+// getVersionTag returns the tag of the version, the tag is used as is since it could be prefixed with v
+func getVersionTag(tags []string, version string) string {
+	for _, tag := range tags {
+		if strings.TrimPrefix(tag, "v") == version {
+			return tag
+		}
+	}
+	return ""
+}
+
+// selectInstalledProvider selects the locked release of the provider in the plugin dir
+// and verifies the checksum of its binary for the platform
+func (r *KformParser) selectInstalledProvider(ctx context.Context, pkg *address.Package, locked *lockv1alpha1.ProviderLock) error {
+	if r.cfg.PluginDir == "" {
+		return fmt.Errorf("cannot locate provider w/o plugin directory, set %s", getProviderEnv(pkg.Address.Name))
+	}
+	if locked == nil {
+		return fmt.Errorf("provider is not locked, run kform init")
+	}
+	entries, err := os.ReadDir(filepath.Join(r.cfg.PluginDir, pkg.BasePath()))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	installed := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && fsys.FileExists(filepath.Join(r.cfg.PluginDir, pkg.ExecPathWithVersion(entry.Name()))) {
+			installed = append(installed, entry.Name())
+		}
+	}
+	if len(installed) == 0 {
+		return fmt.Errorf("provider is not installed, run kform init")
+	}
+	pkg.AddAvailableVersions(installed)
+	if err := pkg.SelectVersion(locked.Version); err != nil {
+		return fmt.Errorf("locked %s, run kform init", err.Error())
+	}
+	checksums := locked.GetPlatform(pkg.Platform.String())
+	if checksums == nil {
+		return fmt.Errorf("lock file has no checksums for platform %s, run kform init", pkg.Platform.String())
+	}
+	return verifyBinaryChecksum(filepath.Join(r.cfg.PluginDir, pkg.ExecPath()), checksums)
+}
+
+// writeProviderPackage writes the files of the provider package in the dir, archives in the
+// package are unpacked. The package is written in a temporary dir first, such that an
+// interrupted install or a concurrent install by another process does not result in a
+// partial package.
+func writeProviderPackage(ctx context.Context, dir string, pkgData store.Storer[[]byte]) error {
+	files := memory.NewStore[[]byte](nil)
+	var errm error
+	pkgData.List(func(k store.Key, b []byte) {
+		if strings.HasSuffix(k.Name, ".tgz") || strings.HasSuffix(k.Name, ".tar.gz") {
+			if err := oci.UnzipTgz(ctx, filepath.Dir(k.Name), b, files); err != nil {
+				errm = errors.Join(errm, err)
 			}
-	*/
+			return
+		}
+		files.Update(k, b)
+	})
+	if errm != nil {
+		return errm
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	// the temporary dir is created with mode 0700
+	if err := os.Chmod(tmpDir, 0755); err != nil {
+		return err
+	}
+	files.List(func(k store.Key, b []byte) {
+		path := filepath.Join(tmpDir, k.Name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			errm = errors.Join(errm, err)
+			return
+		}
+		// the files in the image directory are binaries
+		perm := fs.FileMode(0644)
+		if strings.HasPrefix(filepath.Clean(k.Name), providerImageDir+string(filepath.Separator)) {
+			perm = 0755
+		}
+		if err := os.WriteFile(path, b, perm); err != nil {
+			errm = errors.Join(errm, err)
+		}
+	})
+	if errm != nil {
+		return errm
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		// another process installed the package concurrently
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return nil
+		}
+		return err
+	}
+	return nil
 }
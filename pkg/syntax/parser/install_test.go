@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	lockv1alpha1 "github.com/kform-dev/kform/apis/lock/v1alpha1"
+	"github.com/kform-dev/kform/pkg/fsys"
+	"github.com/kform-dev/kform/pkg/syntax/address"
+)
+
+func TestSelectInstalledProvider(t *testing.T) {
+	cases := map[string]struct {
+		installed       []string
+		lockedVersion   string
+		constraint      string
+		tampered        bool
+		expectedVersion string
+		expectedErr     bool
+	}{
+		"NotInstalled": {
+			lockedVersion: "0.0.1",
+			expectedErr:   true,
+		},
+		"NotLocked": {
+			installed:   []string{"0.0.1"},
+			expectedErr: true,
+		},
+		"Locked": {
+			installed:       []string{"0.0.1", "0.0.2"},
+			lockedVersion:   "0.0.1",
+			expectedVersion: "0.0.1",
+		},
+		"LockedNotInstalled": {
+			installed:     []string{"0.0.1"},
+			lockedVersion: "0.0.2",
+			expectedErr:   true,
+		},
+		"LockedNotMeetingConstraint": {
+			installed:     []string{"0.0.1", "0.0.2"},
+			lockedVersion: "0.0.2",
+			constraint:    "< 0.0.2",
+			expectedErr:   true,
+		},
+		"ChecksumMismatch": {
+			installed:     []string{"0.0.1"},
+			lockedVersion: "0.0.1",
+			tampered:      true,
+			expectedErr:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &KformParser{cfg: &Config{PluginDir: t.TempDir()}}
+			pkg := getTestProviderPackage(t)
+			for _, version := range tc.installed {
+				pkgData := memory.NewStore[[]byte](nil)
+				pkgData.Create(store.ToKey("image.tgz"), getTestTgz(t, map[string]string{
+					filepath.Join(providerImageDir, pkg.Address.Name): "binary",
+				}))
+				if err := writeProviderPackage(ctx, filepath.Join(r.cfg.PluginDir, pkg.FilePath(version)), pkgData); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+			var locked *lockv1alpha1.ProviderLock
+			if tc.lockedVersion != "" {
+				execPath := filepath.Join(r.cfg.PluginDir, pkg.ExecPathWithVersion(tc.lockedVersion))
+				binaryChecksum := "sha256:0"
+				if fsys.FileExists(execPath) {
+					var err error
+					if binaryChecksum, err = getBinaryChecksum(execPath); err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+				}
+				if tc.tampered {
+					if err := os.WriteFile(execPath, []byte("tampered"), 0755); err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+				}
+				locked = &lockv1alpha1.ProviderLock{
+					Version: tc.lockedVersion,
+					Platforms: map[string]*lockv1alpha1.PlatformChecksums{
+						pkg.Platform.String(): {Manifest: "sha256:0", Binary: binaryChecksum},
+					},
+				}
+			}
+			if tc.constraint != "" {
+				pkg.AddConstraints(tc.constraint)
+			}
+			err := r.selectInstalledProvider(ctx, pkg, locked)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if pkg.SelectedVersion != tc.expectedVersion {
+				t.Errorf("want version %s, got: %s", tc.expectedVersion, pkg.SelectedVersion)
+			}
+			info, err := os.Stat(filepath.Join(r.cfg.PluginDir, pkg.ExecPath()))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if info.Mode().Perm()&0100 == 0 {
+				t.Errorf("want executable provider binary, got: %s", info.Mode())
+			}
+		})
+	}
+}
+
+func getTestProviderPackage(t *testing.T) *address.Package {
+	t.Helper()
+	pkg, err := address.GetPackage(store.ToKey("kubernetes"), "europe-docker.pkg.dev/srlinux/eu.gcr.io")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return pkg
+}
+
+func getTestTgz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	lockv1alpha1 "github.com/kform-dev/kform/apis/lock/v1alpha1"
+	"github.com/kform-dev/kform/pkg/fsys"
+)
+
+const (
+	// the lock file of a package is located in <PKG-DIR>/.kform/kform.lock.yaml
+	kformLockFile = "kform.lock.yaml"
+	// checksumPrefix aligns the binary checksums with the OCI digest notation
+	checksumPrefix = "sha256:"
+)
+
+// GetLockFile returns the path of the lock file of the package at path
+func GetLockFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(path, kformDir, kformLockFile)
+}
+
+// ReadLockFile reads the lock file, an empty lock is returned when the lock file does not exist
+func ReadLockFile(path string) (*lockv1alpha1.Lock, error) {
+	if path == "" || !fsys.FileExists(path) {
+		return lockv1alpha1.BuildLock(), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read lock file %s, err: %s", path, err.Error())
+	}
+	lock, err := lockv1alpha1.ParseLock(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse lock file %s, err: %s", path, err.Error())
+	}
+	return lock, nil
+}
+
+func writeLockFile(path string, lock *lockv1alpha1.Lock) error {
+	b, err := lock.Marshal()
+	if err != nil {
+		return fmt.Errorf("cannot marshal lock file, err: %s", err.Error())
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return fmt.Errorf("cannot write lock file %s, err: %s", path, err.Error())
+	}
+	return nil
+}
+
+// getBinaryChecksum returns the sha256 checksum of the provider binary
+func getBinaryChecksum(path string) (string, error) {
+	hash, err := fsys.NewDiskFS(filepath.Dir(path)).Sha256(filepath.Base(path))
+	if err != nil {
+		return "", fmt.Errorf("cannot calculate checksum of %s, err: %s", path, err.Error())
+	}
+	return checksumPrefix + hash, nil
+}
+
+// verifyBinaryChecksum verifies the checksum of the provider binary against the checksum of the lock file
+func verifyBinaryChecksum(path string, checksums *lockv1alpha1.PlatformChecksums) error {
+	checksum, err := getBinaryChecksum(path)
+	if err != nil {
+		return err
+	}
+	if checksum != checksums.Binary {
+		return fmt.Errorf("checksum of %s does not match the lock file, want: %s, got: %s", path, checksums.Binary, checksum)
+	}
+	return nil
+}
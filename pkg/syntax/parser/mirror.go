@@ -0,0 +1,109 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/henderiw/store"
+	lockv1alpha1 "github.com/kform-dev/kform/apis/lock/v1alpha1"
+	"github.com/kform-dev/kform/pkg/pkgio/oras"
+	"github.com/kform-dev/kform/pkg/syntax/address"
+)
+
+// MirrorProviders copies the provider releases locked by the lock file, for all locked platforms,
+// from the registries into OCI image layouts in the filesystem mirror dir. It returns the
+// references of the mirrored releases.
+func MirrorProviders(ctx context.Context, lockFile, dir string) ([]string, error) {
+	lock, err := ReadLockFile(lockFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(lock.Spec.Providers) == 0 {
+		return nil, fmt.Errorf("no providers locked in %s, run kform init", lockFile)
+	}
+	names := make([]string, 0, len(lock.Spec.Providers))
+	for name := range lock.Spec.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mirrored := []string{}
+	var errm error
+	for _, name := range names {
+		providerLock := lock.Spec.Providers[name]
+		platforms := make([]string, 0, len(providerLock.Platforms))
+		for platform := range providerLock.Platforms {
+			platforms = append(platforms, platform)
+		}
+		sort.Strings(platforms)
+		for _, platform := range platforms {
+			ref, err := mirrorProvider(ctx, dir, name, providerLock, platform)
+			if err != nil {
+				errm = errors.Join(errm, fmt.Errorf("provider %s, platform %s, err: %s", name, platform, err.Error()))
+				continue
+			}
+			mirrored = append(mirrored, ref)
+		}
+	}
+	return mirrored, errm
+}
+
+// mirrorProvider copies the locked release of the provider for the platform into the mirror dir,
+// the release is copied by digest such that the mirrored release is the locked one
+func mirrorProvider(ctx context.Context, dir, name string, providerLock *lockv1alpha1.ProviderLock, platform string) (string, error) {
+	pkg, err := address.GetPackage(store.ToKey(name), providerLock.Source)
+	if err != nil {
+		return "", err
+	}
+	goos, goarch, ok := strings.Cut(platform, "_")
+	if !ok {
+		return "", fmt.Errorf("invalid platform, expecting <os>_<arch>")
+	}
+	pkg.Platform = &address.Platform{OS: goos, Arch: goarch}
+
+	tags, err := oras.GetTags(ctx, pkg.GetRef())
+	if err != nil {
+		return "", err
+	}
+	tag := getVersionTag(tags, providerLock.Version)
+	if tag == "" {
+		return "", fmt.Errorf("version %s not found in %s", providerLock.Version, pkg.GetRef())
+	}
+	ref := fmt.Sprintf("%s:%s", pkg.GetRef(), tag)
+	manifest := ""
+	if checksums := providerLock.GetPlatform(platform); checksums != nil {
+		manifest = checksums.Manifest
+	}
+	// releases installed from an archive have no manifest and are copied by tag
+	srcRef := ref
+	if manifest != "" {
+		srcRef = fmt.Sprintf("%s@%s", pkg.GetRef(), manifest)
+	}
+	digest, err := oras.CopyToLayout(ctx, srcRef, GetMirrorLayoutDir(dir, pkg), tag)
+	if err != nil {
+		return "", err
+	}
+	if manifest != "" && manifest != digest {
+		return "", fmt.Errorf("manifest digest of %s does not match the lock file, want: %s, got: %s", ref, manifest, digest)
+	}
+	return ref, nil
+}
@@ -19,6 +19,7 @@ package parser
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/henderiw/store"
 	"github.com/henderiw/store/memory"
@@ -26,6 +27,7 @@ import (
 	"github.com/kform-dev/kform/pkg/pkgio"
 	"github.com/kform-dev/kform/pkg/recorder"
 	"github.com/kform-dev/kform/pkg/recorder/diag"
+	"github.com/kform-dev/kform/pkg/syntax/address"
 	"github.com/kform-dev/kform/pkg/syntax/parser/pkgparser"
 	"github.com/kform-dev/kform/pkg/syntax/types"
 	"github.com/kform-dev/kform/pkg/util/cctx"
@@ -36,6 +38,22 @@ type Config struct {
 	PackageName  string
 	Path         string
 	ResourceData store.Storer[[]byte]
+	// PluginDir is the directory in which the providers are installed
+	PluginDir string
+	// PluginCacheDir is the directory of the plugin cache shared by all packages,
+	// when empty the providers are not cached
+	PluginCacheDir string
+	// ProviderMirrorDir is the filesystem mirror the providers are installed from,
+	// when empty the providers are installed from the registries
+	ProviderMirrorDir string
+	// LockFile is the file that records the installed provider releases
+	LockFile string
+	// InstallProviders installs the providers in the PluginDir, otherwise the
+	// installed providers are validated against the LockFile
+	InstallProviders bool
+	// UpgradeProviders ignores the LockFile when installing the providers, such that
+	// the newest releases meeting the version constraints are selected
+	UpgradeProviders bool
 }
 
 // NewKformParser creates a new kform parser
@@ -51,7 +69,7 @@ func NewKformParser(ctx context.Context, cfg *Config) (*KformParser, error) {
 		rootPackageName: fmt.Sprintf("%s.%s", kformv1alpha1.BlockTYPE_PACKAGE.String(), cfg.PackageName),
 		recorder:        recorder,
 		packages:        memory.NewStore[*types.Package](nil),
-		//providers:      memory.NewStore[*address.Package](),
+		providers:       map[string]*address.Package{},
 	}, nil
 }
 
@@ -61,6 +79,8 @@ type KformParser struct {
 	rootPackageName string
 	recorder        recorder.Recorder[diag.Diagnostic]
 	packages        store.Storer[*types.Package]
+	// providers are the provider packages resolved from the provider requirements
+	providers map[string]*address.Package
 }
 
 func (r *KformParser) Parse(ctx context.Context) {
@@ -74,6 +94,8 @@ func (r *KformParser) Parse(ctx context.Context) {
 
 	r.validateProviderConfigs(ctx)
 	r.validateMixins(ctx)
+	// values passed through the mixins are sensitive when they are derived from sensitive blocks
+	r.propagateSensitive(ctx)
 	r.validateUnreferencedProviderConfigs(ctx)
 	r.validateUnreferencedProviderRequirements(ctx)
 	r.validateProviderRequirements(ctx)
@@ -94,6 +116,48 @@ func (r *KformParser) Parse(ctx context.Context) {
 	r.generateDAG(ctx)
 }
 
+// propagateSensitive propagates the sensitivity across the package boundaries of the mixins.
+// An input of a mixin is sensitive when its value references a sensitive block of the parent
+// package, a block of the parent package is sensitive when it references a sensitive output
+// of the mixin. Within a package the sensitivity is propagated by the package itself.
+func (r *KformParser) propagateSensitive(ctx context.Context) {
+	packages := r.ListPackages(ctx)
+	for changed := true; changed; {
+		changed = false
+		for _, pkg := range packages {
+			for mixinPackageName, mixin := range types.ListBlocks(ctx, pkg.Blocks, types.ListBlockOptions{
+				Prefix: kformv1alpha1.BlockTYPE_PACKAGE.String(),
+			}) {
+				mixinPkg, ok := packages[mixinPackageName]
+				if !ok {
+					// a missing mixin is reported by the mixin validation
+					continue
+				}
+				sensitiveBlocks := pkg.ListSensitiveBlocks(ctx)
+				inputs := []string{}
+				for inputName, value := range mixin.GetInputParameters() {
+					if types.ValueReferences(ctx, value, sensitiveBlocks) {
+						inputs = append(inputs, fmt.Sprintf("%s.%s", kformv1alpha1.BlockTYPE_INPUT.String(), inputName))
+					}
+				}
+				if mixinPkg.MarkSensitive(ctx, inputs...) {
+					changed = true
+				}
+
+				outputs := []string{}
+				for _, outputName := range mixinPkg.ListSensitiveBlocks(ctx, types.ListBlockOptions{
+					Prefix: kformv1alpha1.BlockTYPE_OUTPUT.String(),
+				}) {
+					outputs = append(outputs, fmt.Sprintf("%s.%s", mixinPackageName, strings.TrimPrefix(outputName, kformv1alpha1.BlockTYPE_OUTPUT.String()+".")))
+				}
+				if pkg.MarkSensitive(ctx, pkg.ListReferencingBlocks(ctx, outputs)...) {
+					changed = true
+				}
+			}
+		}
+	}
+}
+
 func (r *KformParser) parsePackage(ctx context.Context, packageName string, pkgType types.PackageKind, path string, data store.Storer[[]byte]) {
 	ctx = context.WithValue(ctx, types.CtxKeyPackageName, packageName)
 	//if r.rootPackagePath == path {
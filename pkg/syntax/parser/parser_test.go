@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	"github.com/kform-dev/kform/pkg/pkgio"
+	"github.com/kform-dev/kform/pkg/recorder"
+	"github.com/kform-dev/kform/pkg/recorder/diag"
+	"github.com/kform-dev/kform/pkg/syntax/parser/pkgparser"
+	"github.com/kform-dev/kform/pkg/syntax/types"
+)
+
+func TestPropagateSensitive(t *testing.T) {
+	rootData := map[string]string{
+		"input.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: password
+  annotations:
+    kform.dev/block-type: input
+    kform.dev/sensitive: "true"
+    kform.dev/default: "true"
+data:
+  value: secret
+`,
+		"mixin.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: db
+  annotations:
+    kform.dev/block-type: package
+    kform.dev/resource-id: db
+`,
+		"connection.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: connection
+  annotations:
+    kform.dev/block-type: output
+    kform.dev/resource-id: connection
+data:
+  connection: package.db.connection
+`,
+		"name.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: name
+  annotations:
+    kform.dev/block-type: output
+    kform.dev/resource-id: name
+data:
+  name: package.db.name
+`,
+	}
+	mixinData := map[string]string{
+		"input.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: password
+  annotations:
+    kform.dev/block-type: input
+    kform.dev/default: "true"
+data:
+  value: ""
+`,
+		"connection.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: connection
+  annotations:
+    kform.dev/block-type: output
+    kform.dev/resource-id: connection
+data:
+  connection: input.password[0].data.value
+`,
+		"name.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: name
+  annotations:
+    kform.dev/block-type: output
+    kform.dev/resource-id: name
+data:
+  name: db
+`,
+	}
+
+	ctx := context.Background()
+	rec := recorder.New[diag.Diagnostic]()
+	ctx = context.WithValue(ctx, types.CtxKeyRecorder, rec)
+	r := &KformParser{
+		recorder: rec,
+		packages: memory.NewStore[*types.Package](nil),
+	}
+	rootPkg := parseTestPackage(ctx, t, "root", types.PackageKind_ROOT, rootData)
+	mixinPkg := parseTestPackage(ctx, t, "package.db", types.PackageKind_MIXIN, mixinData)
+	r.packages.Create(store.ToKey("root"), rootPkg)
+	r.packages.Create(store.ToKey("package.db"), mixinPkg)
+
+	// the mixin passes the sensitive input of the root package to its input
+	mixin, err := rootPkg.Blocks.Get(store.ToKey("package.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	mixin.GetAttributes().InputParameters = map[string]any{
+		"password": "input.password[0].data.value",
+	}
+
+	r.propagateSensitive(ctx)
+
+	cases := map[string]struct {
+		pkg       *types.Package
+		blockName string
+		sensitive bool
+	}{
+		"MixinInput":         {pkg: mixinPkg, blockName: "input.password", sensitive: true},
+		"MixinOutput":        {pkg: mixinPkg, blockName: "output.connection", sensitive: true},
+		"MixinOtherOutput":   {pkg: mixinPkg, blockName: "output.name"},
+		"ParentDependent":    {pkg: rootPkg, blockName: "output.connection", sensitive: true},
+		"ParentOtherOutput":  {pkg: rootPkg, blockName: "output.name"},
+		"ParentMixinPackage": {pkg: rootPkg, blockName: "package.db"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b, err := tc.pkg.Blocks.Get(store.ToKey(tc.blockName))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := b.GetAttributes().IsSensitive(); got != tc.sensitive {
+				t.Errorf("want sensitive %t, got: %t", tc.sensitive, got)
+			}
+		})
+	}
+}
+
+func parseTestPackage(ctx context.Context, t *testing.T, packageName string, kind types.PackageKind, files map[string]string) *types.Package {
+	t.Helper()
+	ctx = context.WithValue(ctx, types.CtxKeyPackageName, packageName)
+	ctx = context.WithValue(ctx, types.CtxKeyPackageKind, kind)
+	pkgData := memory.NewStore[[]byte](nil)
+	for fileName, data := range files {
+		pkgData.Create(store.ToKey(fileName), []byte(data))
+	}
+	kformDataStore, err := (&pkgio.KformMemReader{Data: pkgData}).Read(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pkgParser, err := pkgparser.New(ctx, packageName)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pkg := pkgParser.Parse(ctx, kformDataStore)
+	rec := ctx.Value(types.CtxKeyRecorder).(recorder.Recorder[diag.Diagnostic])
+	if rec.Get().HasError() {
+		t.Fatalf("unexpected error: %s", rec.Get().Error())
+	}
+	return pkg
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkgparser
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/henderiw/store"
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	"github.com/kform-dev/kform/pkg/recorder/diag"
+	"github.com/kform-dev/kform/pkg/syntax/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// isKformFile returns true if the resource is a KformFile
+func isKformFile(rn *yaml.RNode) bool {
+	return rn.GetApiVersion() == kformv1alpha1.APIVersion && rn.GetKind() == kformv1alpha1.KformFileKind
+}
+
+// getKformFile removes the KformFile from the kformDataStore, such that it is not processed as a block,
+// and returns it. A package has at most one KformFile, nil is returned when the package has none.
+func (r *PackageParser) getKformFile(ctx context.Context, kformDataStore store.Storer[*yaml.RNode]) *kformv1alpha1.KformFile {
+	keys := []store.Key{}
+	kformDataStore.List(func(key store.Key, rn *yaml.RNode) {
+		if isKformFile(rn) {
+			keys = append(keys, key)
+		}
+	})
+	if len(keys) == 0 {
+		return nil
+	}
+	if len(keys) > 1 {
+		fileNames := make([]string, 0, len(keys))
+		for _, key := range keys {
+			fileNames = append(fileNames, key.Name)
+		}
+		sort.Strings(fileNames)
+		r.recorder.Record(diag.DiagErrorfWithContext(types.Context{Context: ctx}.String(), "a package can only have one %s, got: %v", kformv1alpha1.KformFileKind, fileNames))
+		return nil
+	}
+	rn, err := kformDataStore.Get(keys[0])
+	if err != nil {
+		r.recorder.Record(diag.DiagFromErr(err))
+		return nil
+	}
+	if err := kformDataStore.Delete(keys[0]); err != nil {
+		r.recorder.Record(diag.DiagFromErr(err))
+		return nil
+	}
+	ctx = context.WithValue(ctx, types.CtxKeyFileName, keys[0].Name)
+	kf := &kformv1alpha1.KformFile{}
+	if err := k8syaml.Unmarshal([]byte(rn.MustString()), kf); err != nil {
+		r.recorder.Record(diag.DiagFromErrWithContext(types.Context{Context: ctx}.String(), fmt.Errorf("cannot parse %s, err: %s", kformv1alpha1.KformFileKind, err.Error())))
+		return nil
+	}
+	return kf
+}
+
+// addProviderRequirements validates the provider requirements of the KformFile and adds
+// them to the provider requirements of the package
+func (r *PackageParser) addProviderRequirements(ctx context.Context, pkg *types.Package, kf *kformv1alpha1.KformFile) {
+	for providerName, providerReq := range kf.Spec.ProviderRequirements {
+		if err := providerReq.Validate(); err != nil {
+			r.recorder.Record(diag.DiagFromErrWithContext(types.Context{Context: ctx}.String(), fmt.Errorf("invalid provider requirement %s, err: %s", providerName, err.Error())))
+			continue
+		}
+		if err := pkg.ProviderRequirements.Create(store.ToKey(providerName), providerReq); err != nil {
+			r.recorder.Record(diag.DiagErrorf("cannot add provider %s in provider requirements, err: %s", providerName, err.Error()))
+		}
+	}
+}
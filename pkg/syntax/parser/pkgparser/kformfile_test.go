@@ -0,0 +1,120 @@
+package pkgparser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	"github.com/kform-dev/kform/pkg/pkgio"
+	"github.com/kform-dev/kform/pkg/recorder"
+	"github.com/kform-dev/kform/pkg/recorder/diag"
+	"github.com/kform-dev/kform/pkg/syntax/types"
+)
+
+const kformFile = `apiVersion: meta.pkg.kform.dev/v1alpha1
+kind: KformFile
+metadata:
+  name: test
+spec:
+  providerRequirements:
+    kubernetes:
+      source: europe-docker.pkg.dev/srlinux/eu.gcr.io
+      version: ">= 0.0.1, < 0.1.0"
+    local:
+      source: ""
+      version: ""
+`
+
+const invalidSourceKformFile = `apiVersion: meta.pkg.kform.dev/v1alpha1
+kind: KformFile
+metadata:
+  name: test
+spec:
+  providerRequirements:
+    kubernetes:
+      source: europe-docker.pkg.dev
+      version: ">= 0.0.1"
+`
+
+const invalidVersionKformFile = `apiVersion: meta.pkg.kform.dev/v1alpha1
+kind: KformFile
+metadata:
+  name: test
+spec:
+  providerRequirements:
+    kubernetes:
+      source: europe-docker.pkg.dev/srlinux/eu.gcr.io
+      version: "> bla"
+`
+
+func TestKformFile(t *testing.T) {
+	cases := map[string]struct {
+		files                map[string]string
+		expectedRequirements map[string]kformv1alpha1.Provider
+		expectedErr          bool
+	}{
+		"NoKformFile": {
+			files:                map[string]string{},
+			expectedRequirements: map[string]kformv1alpha1.Provider{},
+		},
+		"KformFile": {
+			files: map[string]string{kformv1alpha1.KformFileName: kformFile},
+			expectedRequirements: map[string]kformv1alpha1.Provider{
+				"kubernetes": {Source: "europe-docker.pkg.dev/srlinux/eu.gcr.io", Version: ">= 0.0.1, < 0.1.0"},
+				"local":      {},
+			},
+		},
+		"MultipleKformFiles": {
+			files:       map[string]string{kformv1alpha1.KformFileName: kformFile, "other.yaml": kformFile},
+			expectedErr: true,
+		},
+		"InvalidSource": {
+			files:       map[string]string{kformv1alpha1.KformFileName: invalidSourceKformFile},
+			expectedErr: true,
+		},
+		"InvalidVersion": {
+			files:       map[string]string{kformv1alpha1.KformFileName: invalidVersionKformFile},
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			rec := recorder.New[diag.Diagnostic]()
+			ctx = context.WithValue(ctx, types.CtxKeyRecorder, rec)
+			ctx = context.WithValue(ctx, types.CtxKeyPackageName, "root")
+			ctx = context.WithValue(ctx, types.CtxKeyPackageKind, types.PackageKind_ROOT)
+
+			data := memory.NewStore[[]byte](nil)
+			for fileName, s := range tc.files {
+				data.Create(store.ToKey(fileName), []byte(s))
+			}
+			reader := pkgio.KformMemReader{Data: data}
+			kformDataStore, err := reader.Read(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			p, err := New(ctx, "root")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			pkg := p.Parse(ctx, kformDataStore)
+			if tc.expectedErr {
+				if !rec.Get().HasError() {
+					t.Fatalf("want error, got nil")
+				}
+				return
+			}
+			if rec.Get().HasError() {
+				t.Fatalf("unexpected error: %s", rec.Get().Error())
+			}
+			if diff := cmp.Diff(tc.expectedRequirements, pkg.ListProviderRequirements(ctx)); diff != "" {
+				t.Errorf("-want requirements, +got:\n%s", diff)
+			}
+		})
+	}
+}
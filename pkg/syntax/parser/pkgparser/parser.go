@@ -55,27 +55,13 @@ func (r *PackageParser) Parse(ctx context.Context, kformDataStore store.Storer[*
 		cctx.GetContextValue[types.PackageKind](ctx, types.CtxKeyPackageKind),
 		r.recorder,
 	)
-	// TODO is kform file mandatory
-	//r.recorder.Record(diag.DiagErrorf("cannot parse module with a kform file"))
-	//return nil
-	// add the required providers in the module
-	/*
-		if kf != nil {
-			for providerRawName, providerReq := range kf.Spec.ProviderRequirements {
-				if err := providerReq.Validate(); err != nil {
-					r.recorder.Record(diag.DiagErrorf("cannot parse module provider requirement invalid for %s, err: %s", providerRawName, err.Error()))
-					return nil
-				}
-				if err := pkg.ProviderRequirements.Create(
-					ctx,
-					store.ToKey(providerRawName),
-					providerReq,
-				); err != nil {
-					r.recorder.Record(diag.DiagErrorf("cannot add provider %s in provider requirements, err: %s", providerRawName, err.Error()))
-				}
-			}
-		}
-	*/
+	// the KformFile is optional, when present its provider requirements are added to the package
+	if kf := r.getKformFile(ctx, kformDataStore); kf != nil {
+		r.addProviderRequirements(ctx, pkg, kf)
+	}
+	if r.recorder.Get().HasError() {
+		return nil
+	}
 
 	ctx = context.WithValue(ctx, types.CtxKeyPackage, pkg)
 	r.validate(ctx, kformDataStore)
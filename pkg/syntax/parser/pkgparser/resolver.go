@@ -24,6 +24,8 @@ import (
 func (r *PackageParser) resolve(ctx context.Context, pkg *types.Package) {
 	// check the resources in the relevant lists to see if the dependency existss
 	pkg.ResolveDAGDependencies(ctx)
+	// values derived from sensitive blocks are sensitive as well
+	pkg.PropagateSensitive(ctx)
 
 	// for each resource we should have a required provider in root/mixin packages
 	if pkg.Kind == types.PackageKind_ROOT {
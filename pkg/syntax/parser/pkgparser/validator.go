@@ -50,7 +50,8 @@ func (r *PackageParser) validate(ctx context.Context, kformDataStore store.Store
 		wg.Add(1)
 		go func(rn *yaml.RNode) {
 			defer wg.Done()
-			ctx = context.WithValue(ctx, types.CtxKeyFileName, key.Name)
+			// derive a ctx per block, the ctx of the package is shared by the goroutines
+			ctx := context.WithValue(ctx, types.CtxKeyFileName, key.Name)
 			ctx = context.WithValue(ctx, types.CtxKeyIndex, key.Namespace)
 			r.processBlock(ctx, rn)
 		}(rn)
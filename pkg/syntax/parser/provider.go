@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/henderiw/store"
@@ -92,7 +93,7 @@ func (r *KformParser) InitProviders(ctx context.Context) (store.Storer[types.Pro
 		return nil, err
 	}
 	for _, providerName := range rawProviders.UnsortedList() {
-		provider, err := CreateProvider(ctx, providerName)
+		provider, err := CreateProvider(ctx, providerName, r.getProviderExecPath(providerName))
 		if err != nil {
 			return nil, err
 		}
@@ -115,12 +116,30 @@ func (r *KformParser) GetEmptyProviderInstances(ctx context.Context) (store.Stor
 	return providerInstances, nil
 }
 
-func CreateProvider(ctx context.Context, providerName string) (types.Provider, error) {
+// getProviderExecPath returns the path of the binary of the provider installed in the
+// plugin dir, an empty path when the provider is local
+func (r *KformParser) getProviderExecPath(providerName string) string {
+	pkg, ok := r.providers[providerName]
+	if !ok || pkg.IsLocal() || pkg.SelectedVersion == "" {
+		return ""
+	}
+	return filepath.Join(r.cfg.PluginDir, pkg.ExecPath())
+}
+
+func getProviderEnv(providerName string) string {
+	return fmt.Sprintf("KFORM_PROVIDER_%s", strings.ToUpper(providerName))
+}
+
+// CreateProvider initializes the provider with the binary at execPath. The KFORM_PROVIDER_<NAME>
+// env variable overrides the location of the binary, which locates local providers.
+func CreateProvider(ctx context.Context, providerName, execPath string) (types.Provider, error) {
 	provider := types.Provider{}
-	providerEnv := fmt.Sprintf("KFORM_PROVIDER_%s", strings.ToUpper(providerName))
-	providerExecPath, found := os.LookupEnv(providerEnv)
+	providerExecPath, found := os.LookupEnv(getProviderEnv(providerName))
 	if !found {
-		return provider, fmt.Errorf("kform provider location has to be specified using env variable for now: %s", providerExecPath)
+		if execPath == "" {
+			return provider, fmt.Errorf("kform provider %s is not installed, local providers have to be specified using env variable: %s", providerName, getProviderEnv(providerName))
+		}
+		providerExecPath = execPath
 	}
 
 	if err := provider.Init(ctx, providerExecPath, providerName); err != nil {
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 Nokia.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/henderiw/logger/log"
+	"github.com/henderiw/store"
+	"github.com/henderiw/store/memory"
+	"github.com/kform-dev/kform/pkg/fsys"
+	"github.com/kform-dev/kform/pkg/pkgio/oras"
+	"github.com/kform-dev/kform/pkg/syntax/address"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// providerSource is the origin the provider releases are installed from
+type providerSource interface {
+	// GetTags returns the tags of the releases of the provider package
+	GetTags(ctx context.Context, pkg *address.Package) ([]string, error)
+	// Resolve returns the manifest digest of the release with the tag, an empty string
+	// when the source has no manifests
+	Resolve(ctx context.Context, pkg *address.Package, tag string) (string, error)
+	// Pull returns the files of the release with the tag and manifest digest
+	Pull(ctx context.Context, pkg *address.Package, tag, digest string) (store.Storer[[]byte], error)
+}
+
+// getProviderSource returns the registry of the provider package, or the filesystem mirror when
+// configured. In the mirror the provider is located at <hostname>/<namespace> either as an OCI
+// image layout per platform <name>_<os>_<arch>, or as release archives <name>_<version>_<os>_<arch>.tar.gz
+func (r *KformParser) getProviderSource(pkg *address.Package) providerSource {
+	if r.cfg.ProviderMirrorDir == "" {
+		return &registrySource{cacheDir: r.cfg.PluginCacheDir}
+	}
+	layoutDir := GetMirrorLayoutDir(r.cfg.ProviderMirrorDir, pkg)
+	if fsys.FileExists(filepath.Join(layoutDir, ocispecv1.ImageLayoutFile)) {
+		return &layoutMirror{dir: layoutDir}
+	}
+	return &archiveMirror{dir: filepath.Dir(layoutDir)}
+}
+
+// GetMirrorLayoutDir returns the directory of the OCI image layout of the provider package
+// for its platform in the filesystem mirror
+func GetMirrorLayoutDir(mirrorDir string, pkg *address.Package) string {
+	return filepath.Join(mirrorDir, pkg.GetRef())
+}
+
+// registrySource installs the provider releases from the OCI registry, through the plugin cache
+type registrySource struct {
+	cacheDir string
+}
+
+func (r *registrySource) GetTags(ctx context.Context, pkg *address.Package) ([]string, error) {
+	return oras.GetTags(ctx, pkg.GetRef())
+}
+
+func (r *registrySource) Resolve(ctx context.Context, pkg *address.Package, tag string) (string, error) {
+	return oras.Resolve(ctx, fmt.Sprintf("%s:%s", pkg.GetRef(), tag))
+}
+
+// Pull returns the files of the release with the manifest digest from the plugin cache, when
+// the release is not cached it is pulled from the registry and added to the cache
+func (r *registrySource) Pull(ctx context.Context, pkg *address.Package, tag, digest string) (store.Storer[[]byte], error) {
+	log := log.FromContext(ctx).With("provider", pkg.GetRef())
+	cache := getPluginCache(r.cacheDir)
+	id := getPluginCacheID(pkg, pkg.SelectedVersion, pkg.Platform.String(), digest)
+	if cache.Has(id) {
+		pkgData := memory.NewStore[[]byte](nil)
+		err := loadProviderPackage(ctx, cache, id, pkgData)
+		if err == nil {
+			log.Debug("provider loaded from plugin cache", "version", pkg.SelectedVersion)
+			return pkgData, nil
+		}
+		log.Warn("cannot load provider from plugin cache", "version", pkg.SelectedVersion, "err", err.Error())
+	}
+	pkgData := memory.NewStore[[]byte](nil)
+	// the release is pulled by digest, such that the installed release is the resolved one
+	if err := oras.Pull(ctx, fmt.Sprintf("%s@%s", pkg.GetRef(), digest), pkgData); err != nil {
+		return nil, err
+	}
+	// a failure to cache the release does not fail the install
+	if err := storeProviderPackage(cache, id, pkgData); err != nil {
+		log.Warn("cannot store provider in plugin cache", "version", pkg.SelectedVersion, "err", err.Error())
+	}
+	return pkgData, nil
+}
+
+// layoutMirror installs the provider releases from an OCI image layout in the filesystem mirror,
+// as populated by kform providers mirror. The manifest digests match the ones of the registry.
+type layoutMirror struct {
+	dir string
+}
+
+func (r *layoutMirror) GetTags(ctx context.Context, pkg *address.Package) ([]string, error) {
+	return oras.GetLayoutTags(ctx, r.dir)
+}
+
+func (r *layoutMirror) Resolve(ctx context.Context, pkg *address.Package, tag string) (string, error) {
+	return oras.ResolveLayout(ctx, r.dir, tag)
+}
+
+func (r *layoutMirror) Pull(ctx context.Context, pkg *address.Package, tag, digest string) (store.Storer[[]byte], error) {
+	pkgData := memory.NewStore[[]byte](nil)
+	if err := oras.PullFromLayout(ctx, r.dir, digest, pkgData); err != nil {
+		return nil, err
+	}
+	return pkgData, nil
+}
+
+// archiveMirror installs the provider releases from the release archives in the filesystem mirror.
+// The archives have no manifest, such that a release is only verified by the checksum of its binary.
+type archiveMirror struct {
+	dir string
+}
+
+func (r *archiveMirror) GetTags(ctx context.Context, pkg *address.Package) ([]string, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("provider %s not found in the filesystem mirror", pkg.GetRef())
+		}
+		return nil, err
+	}
+	tags := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tar.gz") {
+			continue
+		}
+		image, err := address.ParseImageName(entry.Name())
+		if err != nil {
+			continue
+		}
+		// the archives of other providers and platforms are ignored
+		if entry.Name() == pkg.ArchiveFilename(image.Version) {
+			tags = append(tags, image.Version)
+		}
+	}
+	return tags, nil
+}
+
+func (r *archiveMirror) Resolve(ctx context.Context, pkg *address.Package, tag string) (string, error) {
+	return "", nil
+}
+
+// Pull returns the archive as the image of the provider package, the archive is unpacked
+// when the package is written to the plugin dir
+func (r *archiveMirror) Pull(ctx context.Context, pkg *address.Package, tag, digest string) (store.Storer[[]byte], error) {
+	filename := pkg.ArchiveFilename(tag)
+	b, err := os.ReadFile(filepath.Join(r.dir, filename))
+	if err != nil {
+		return nil, err
+	}
+	pkgData := memory.NewStore[[]byte](nil)
+	if err := pkgData.Create(store.ToKey(filepath.Join(providerImageDir, filename)), b); err != nil {
+		return nil, err
+	}
+	return pkgData, nil
+}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lockv1alpha1 "github.com/kform-dev/kform/apis/lock/v1alpha1"
+	"github.com/kform-dev/kform/pkg/pkgio/oras"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	orasgo "oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	ocilayout "oras.land/oras-go/v2/content/oci"
+)
+
+func TestInstallProviderFromMirror(t *testing.T) {
+	cases := map[string]struct {
+		archives         []string
+		layoutTags       []string
+		lockedVersion    string
+		lockedBinary     string
+		expectedVersion  string
+		expectedManifest bool
+		expectedErr      bool
+	}{
+		"NotMirrored": {
+			expectedErr: true,
+		},
+		"Archive": {
+			archives:        []string{"0.0.1", "0.0.2"},
+			expectedVersion: "0.0.2",
+		},
+		"ArchiveLocked": {
+			archives:        []string{"0.0.1", "0.0.2"},
+			lockedVersion:   "0.0.1",
+			expectedVersion: "0.0.1",
+		},
+		"ArchiveChecksumMismatch": {
+			archives:      []string{"0.0.1"},
+			lockedVersion: "0.0.1",
+			lockedBinary:  "sha256:0",
+			expectedErr:   true,
+		},
+		"Layout": {
+			layoutTags:       []string{"v0.0.1", "v0.0.2"},
+			expectedVersion:  "0.0.2",
+			expectedManifest: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			r := &KformParser{cfg: &Config{PluginDir: t.TempDir(), ProviderMirrorDir: t.TempDir()}}
+			pkg := getTestProviderPackage(t)
+			// the release archives contain the binary at the root
+			binary := map[string]string{pkg.Address.Name: "binary"}
+			binaryChecksum := fmt.Sprintf("%s%x", checksumPrefix, sha256.Sum256([]byte("binary")))
+
+			archiveDir := filepath.Dir(GetMirrorLayoutDir(r.cfg.ProviderMirrorDir, pkg))
+			if len(tc.archives) > 0 {
+				// the archives of other platforms and providers are not selected
+				archives := []string{
+					fmt.Sprintf("%s_0.0.9_plan9_386.tar.gz", pkg.Address.Name),
+					fmt.Sprintf("other_0.0.9_%s.tar.gz", pkg.Platform.String()),
+				}
+				for _, version := range tc.archives {
+					archives = append(archives, pkg.ArchiveFilename(version))
+				}
+				if err := os.MkdirAll(archiveDir, 0755); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				for _, archive := range archives {
+					if err := os.WriteFile(filepath.Join(archiveDir, archive), getTestTgz(t, binary), 0644); err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+				}
+			}
+			if len(tc.layoutTags) > 0 {
+				writeTestLayout(t, GetMirrorLayoutDir(r.cfg.ProviderMirrorDir, pkg), tc.layoutTags, getTestTgz(t, map[string]string{
+					filepath.Join(providerImageDir, pkg.Address.Name): "binary",
+				}))
+			}
+
+			var locked *lockv1alpha1.ProviderLock
+			if tc.lockedVersion != "" {
+				lockedBinary := tc.lockedBinary
+				if lockedBinary == "" {
+					lockedBinary = binaryChecksum
+				}
+				locked = &lockv1alpha1.ProviderLock{
+					Version: tc.lockedVersion,
+					Platforms: map[string]*lockv1alpha1.PlatformChecksums{
+						pkg.Platform.String(): {Binary: lockedBinary},
+					},
+				}
+			}
+			providerLock, err := r.installProvider(ctx, pkg, "europe-docker.pkg.dev/srlinux/eu.gcr.io", locked)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if providerLock.Version != tc.expectedVersion {
+				t.Errorf("want version %s, got: %s", tc.expectedVersion, providerLock.Version)
+			}
+			checksums := providerLock.GetPlatform(pkg.Platform.String())
+			if checksums == nil {
+				t.Fatalf("want checksums for platform %s, got nil", pkg.Platform.String())
+			}
+			if (checksums.Manifest != "") != tc.expectedManifest {
+				t.Errorf("want manifest %t, got: %q", tc.expectedManifest, checksums.Manifest)
+			}
+			if checksums.Binary != binaryChecksum {
+				t.Errorf("want binary checksum %s, got: %s", binaryChecksum, checksums.Binary)
+			}
+		})
+	}
+}
+
+// writeTestLayout writes an OCI image layout with a provider release per tag in the dir
+func writeTestLayout(t *testing.T, dir string, tags []string, pkgData []byte) {
+	t.Helper()
+	ctx := context.Background()
+	dst, err := ocilayout.New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	layer := content.NewDescriptorFromBytes(oras.PackageLayerMediaType, pkgData)
+	if err := dst.Push(ctx, layer, bytes.NewReader(pkgData)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	desc, err := orasgo.PackManifest(ctx, dst, orasgo.PackManifestVersion1_1, oras.ProviderMediaType, orasgo.PackManifestOptions{
+		Layers: []ocispecv1.Descriptor{layer},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, tag := range tags {
+		if err := dst.Tag(ctx, desc, tag); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
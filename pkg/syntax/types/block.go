@@ -31,6 +31,7 @@ import (
 
 type Block interface {
 	GetFileName() string
+	GetFileNames() []string
 	GetIndex() string
 	GetPackageName() string
 	GetBlockName() string
@@ -131,6 +132,11 @@ func (r *block) GetFileName() string {
 	return sb.String()
 }
 
+// GetFileNames returns the file name of every data entry of the block
+func (r *block) GetFileNames() []string {
+	return r.fileNames
+}
+
 func (r *block) GetIndex() string {
 	return r.index
 }
@@ -146,6 +152,7 @@ func (r *block) addData(ctx context.Context, rn *yaml.RNode) error {
 		}
 	*/
 	r.data = r.data.Add(rn)
+	r.fileNames = append(r.fileNames, cctx.GetContextValue[string](ctx, CtxKeyFileName))
 	return nil
 }
 
@@ -204,9 +211,9 @@ func getAttributes(ctx context.Context, blockType kformv1alpha1.BlockType, rn *y
 		Source:        annotations[kformv1alpha1.KformAnnotationKey_SOURCE], // TODO MIXIN
 		Alias:         annotations[kformv1alpha1.KformAnnotationKey_ALIAS],
 		HostName:      annotations[kformv1alpha1.KformAnnotationKey_HOSTNAME],
+		Validation:    annotations[kformv1alpha1.KformAnnotationKey_VALIDATION],
 
 		// TODO
-		//Validation:  ko.GetAnnotation(kformv1alpha1.KformAnnotationKey_V),,
 		//Providers: -> TBD maybe we need a dedicated KRM resource for Mixin
 		//Source:        ko.GetAnnotation(kformv1alpha1.KformAnnotationKey_SOURCE), -> TBD maybe we need a dedicated KRM resource for Mixin
 		// Workspaces
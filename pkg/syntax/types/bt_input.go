@@ -37,6 +37,7 @@ func newInput(ctx context.Context) BlockProcessor {
 				kformv1alpha1.KformAnnotationKey_DEFAULT:     optional,
 				kformv1alpha1.KformAnnotationKey_DESCRIPTION: optional,
 				kformv1alpha1.KformAnnotationKey_SENSITIVE:   optional,
+				kformv1alpha1.KformAnnotationKey_VALIDATION:  optional,
 			},
 			recorder: cctx.GetContextValue[recorder.Recorder[diag.Diagnostic]](ctx, CtxKeyRecorder),
 		},
@@ -61,6 +62,10 @@ func (r *input) UpdatePackage(ctx context.Context) {
 	// this records the errors
 	r.validateAnnotations(ctx, rn)
 
+	if _, err := kformv1alpha1.ParseValidation(annotations[kformv1alpha1.KformAnnotationKey_VALIDATION]); err != nil {
+		r.recorder.Record(diag.DiagFromErrWithContext(Context{ctx}.String(), err))
+	}
+
 	pkg := cctx.GetContextValue[*Package](ctx, CtxKeyPackage)
 	if pkg == nil {
 		r.recorder.Record(diag.DiagFromErrWithContext(Context{ctx}.String(), fmt.Errorf("cannot add block without package")))
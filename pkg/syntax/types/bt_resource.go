@@ -67,6 +67,10 @@ func (r *resource) UpdatePackage(ctx context.Context) {
 	// this records the errors
 	r.validateAnnotations(ctx, rn)
 
+	if _, err := kformv1alpha1.ParseLifeCycle(annotations[kformv1alpha1.KformAnnotationKey_LIFECYCLE]); err != nil {
+		r.recorder.Record(diag.DiagFromErrWithContext(Context{ctx}.String(), err))
+	}
+
 	if err := validateResourceSyntax(ctx, resourceType); err != nil {
 		r.recorder.Record(diag.DiagFromErrWithContext(Context{ctx}.String(), err))
 		return
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/henderiw/store"
@@ -32,6 +33,7 @@ import (
 	"github.com/kform-dev/kform/pkg/render2/deprenderer"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func NewPackage(name string, kind PackageKind, recorder recorder.Recorder[diag.Diagnostic]) *Package {
@@ -66,9 +68,7 @@ type Package struct {
 
 func (r *Package) ListBlocks(ctx context.Context) []string {
 	blockNames := []string{}
-	for name := range ListBlocks(ctx, r.Blocks, ListBlockOptions{
-		ExludeOrphan: true,
-	}) {
+	for name := range ListBlocks(ctx, r.Blocks) {
 		blockNames = append(blockNames, name)
 	}
 	return blockNames
@@ -91,13 +91,10 @@ func DeepCopy(in any) (any, error) {
 }
 
 func (r *Package) AddDependencies(ctx context.Context) {
-	// excludes the orphan blocks since these resources are there to avoid
-	// kform from deleting them
 	blocks := r.ListBlocks(ctx)
-	// input blocks and orphan blocks should not have dependencies
+	// input blocks should not have dependencies
 	// so we can exclude them from finding dependencies
 	for blockName, block := range ListBlocks(ctx, r.Blocks, ListBlockOptions{
-		ExludeOrphan: true,
 		PrefixExludes: []string{
 			kformv1alpha1.BlockTYPE_INPUT.String(),
 			kformv1alpha1.BlockType_BACKEND.String(),
@@ -155,6 +152,99 @@ func (r *Package) resolveDependencies(_ context.Context, name string, b Block) {
 	}
 }
 
+// PropagateSensitive marks the blocks that reference a sensitive block as sensitive,
+// such that values derived from a sensitive input/resource are masked as well.
+// The dependencies include the depends_on references, which errs on the side of masking.
+func (r *Package) PropagateSensitive(ctx context.Context) {
+	blocks := ListBlocks(ctx, r.Blocks)
+	for changed := true; changed; {
+		changed = false
+		for _, b := range blocks {
+			attrs := b.GetAttributes()
+			if attrs == nil || attrs.Sensitive {
+				continue
+			}
+			for d := range b.GetDependencies() {
+				if dep, ok := blocks[d]; ok && dep.GetAttributes().IsSensitive() {
+					attrs.Sensitive = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// ListSensitiveBlocks returns the names of the sensitive blocks of the package
+func (r *Package) ListSensitiveBlocks(ctx context.Context, opts ...ListBlockOptions) []string {
+	blockNames := []string{}
+	for blockName, b := range ListBlocks(ctx, r.Blocks, opts...) {
+		if b.GetAttributes().IsSensitive() {
+			blockNames = append(blockNames, blockName)
+		}
+	}
+	sort.Strings(blockNames)
+	return blockNames
+}
+
+// ListReferencingBlocks returns the names of the blocks whose data references any of the refs
+func (r *Package) ListReferencingBlocks(ctx context.Context, refs []string) []string {
+	blockNames := []string{}
+	if len(refs) == 0 {
+		return blockNames
+	}
+	for blockName, b := range ListBlocks(ctx, r.Blocks) {
+		for _, rn := range b.GetData().Get() {
+			// the renderer updates the node, so we render a copy
+			if references(ctx, rn.Copy().YNode(), refs) {
+				blockNames = append(blockNames, blockName)
+				break
+			}
+		}
+	}
+	sort.Strings(blockNames)
+	return blockNames
+}
+
+// MarkSensitive marks the blocks as sensitive and propagates the sensitivity within
+// the package, it returns true when any of the blocks was not sensitive yet
+func (r *Package) MarkSensitive(ctx context.Context, blockNames ...string) bool {
+	changed := false
+	for _, blockName := range blockNames {
+		b, err := r.Blocks.Get(store.ToKey(blockName))
+		if err != nil {
+			continue
+		}
+		attrs := b.GetAttributes()
+		if attrs == nil || attrs.Sensitive {
+			continue
+		}
+		attrs.Sensitive = true
+		changed = true
+	}
+	if changed {
+		r.PropagateSensitive(ctx)
+	}
+	return changed
+}
+
+// ValueReferences returns true when the value references any of the refs
+func ValueReferences(ctx context.Context, value any, refs []string) bool {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return false
+	}
+	return references(ctx, node, refs)
+}
+
+func references(ctx context.Context, node *yaml.Node, refs []string) bool {
+	deprenderer := deprenderer.New(refs)
+	if _, err := deprenderer.Render(ctx, node); err != nil {
+		return false
+	}
+	return deprenderer.GetDependencies(ctx).Len() != 0
+}
+
 func (r *Package) ResolveResource2ProviderConfig(ctx context.Context) {
 	// list resources/data/etc
 	for name, b := range ListBlocks(ctx, r.Blocks, ListBlockOptions{PrefixExludes: []string{
@@ -186,7 +276,6 @@ func (r *Package) ValidateMixinProviderConfigs(ctx context.Context) {
 type ListBlockOptions struct {
 	Prefix        string
 	PrefixExludes []string
-	ExludeOrphan  bool
 }
 
 func ListBlocks(ctx context.Context, s store.Storer[Block], opts ...ListBlockOptions) map[string]Block {
@@ -207,17 +296,7 @@ func ListBlocks(ctx context.Context, s store.Storer[Block], opts ...ListBlockOpt
 					}
 				}
 			}
-			if opts[0].ExludeOrphan {
-				if len(data.GetData().Get()) > 0 {
-					annotations := data.GetData().Get()[0].GetAnnotations()
-					if len(annotations) != 0 && annotations[kformv1alpha1.KformAnnotationKey_LIFECYCLE] != "" {
-						excluded = true
-					}
-				}
-			}
 			if !excluded {
-				// when the excludeOrphan is added we need to check for the lifecycle attribute
-				// and exclude the object from the list
 				blocks[key.Name] = data
 			}
 
@@ -241,7 +320,6 @@ func (r *Package) ListProviderConfigs(ctx context.Context) map[string]Block {
 func (r *Package) ListProvidersFromResources(ctx context.Context) sets.Set[string] {
 	providers := sets.New[string]()
 	for _, block := range ListBlocks(ctx, r.Blocks, ListBlockOptions{
-		ExludeOrphan: true,
 		PrefixExludes: []string{
 			kformv1alpha1.BlockTYPE_INPUT.String(),
 			kformv1alpha1.BlockTYPE_OUTPUT.String(),
@@ -260,7 +338,6 @@ func (r *Package) ListProvidersFromResources(ctx context.Context) sets.Set[strin
 func (r *Package) ListRawProvidersFromResources(ctx context.Context) sets.Set[string] {
 	providers := sets.New[string]()
 	for _, block := range ListBlocks(ctx, r.Blocks, ListBlockOptions{
-		ExludeOrphan: true,
 		PrefixExludes: []string{ // only search for resources
 			kformv1alpha1.BlockTYPE_INPUT.String(),
 			kformv1alpha1.BlockTYPE_OUTPUT.String(),
@@ -285,14 +362,49 @@ func (r *Package) ListProviderRequirements(ctx context.Context) map[string]kform
 func (r *Package) ListResources(ctx context.Context) sets.Set[Block] {
 	resources := sets.New[Block]()
 	for _, block := range ListBlocks(ctx, r.Blocks, ListBlockOptions{
-		ExludeOrphan: true,
-		Prefix:       kformv1alpha1.BlockTYPE_RESOURCE.String(),
+		Prefix: kformv1alpha1.BlockTYPE_RESOURCE.String(),
 	}) {
 		resources.Insert(block)
 	}
 	return resources
 }
 
+// GetResourceDependencies returns per resource block the resource blocks it depends on.
+// The dependencies are resolved transitively through the other blocks in the DAG, such
+// that the order is preserved when only a subset of the resources is actuated.
+func (r *Package) GetResourceDependencies(ctx context.Context) map[string][]string {
+	dependencies := map[string][]string{}
+	if r.DAG == nil {
+		return dependencies
+	}
+	vertices := r.DAG.GetVertices()
+	for vertexName, vertexContext := range vertices {
+		if vertexContext.BlockType != kformv1alpha1.BlockTYPE_RESOURCE {
+			continue
+		}
+		upstream := sets.New[string]()
+		visited := sets.New[string]()
+		var walk func(string)
+		walk = func(n string) {
+			for _, upVertexName := range r.DAG.GetUpVertexes(n) {
+				if visited.Has(upVertexName) {
+					continue
+				}
+				visited.Insert(upVertexName)
+				if v, ok := vertices[upVertexName]; ok && v.BlockType == kformv1alpha1.BlockTYPE_RESOURCE {
+					upstream.Insert(upVertexName)
+				}
+				walk(upVertexName)
+			}
+		}
+		walk(vertexName)
+		if upstream.Len() != 0 {
+			dependencies[vertexName] = sets.List(upstream)
+		}
+	}
+	return dependencies
+}
+
 func (r *Package) GenerateDAG(ctx context.Context, provider bool, usedProviderConfigs sets.Set[string]) error {
 	// add the vertices with the right VertexContext to the dag
 	d, err := r.generateDAG(ctx, provider, usedProviderConfigs)
@@ -359,7 +471,6 @@ func (r *Package) generateDAG(ctx context.Context, provider bool, usedProviderCo
 		// - resources
 		// - providers
 		for blockName, block := range ListBlocks(ctx, r.Blocks, ListBlockOptions{
-			ExludeOrphan:  true,
 			PrefixExludes: []string{kformv1alpha1.BlockType_BACKEND.String()},
 		}) {
 			if err := addVertex(ctx, d, blockName, block); err != nil {
@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// keySelf references the validated value in the rules
+const keySelf = "self"
+
+// Validate validates the value against the schema and the rules of the validation,
+// the rules are only evaluated when the value complies with the schema.
+// Every violation is returned as a separate error which identifies the offending field or rule.
+func Validate(validation *kformv1alpha1.Validation, value any) []error {
+	if validation == nil {
+		return nil
+	}
+	// normalize the value to json types, which are expected by the schema validator and cel
+	value, err := toJSON(value)
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := []error{}
+	if validation.Schema != nil {
+		result := validate.NewSchemaValidator(validation.Schema, nil, "", strfmt.Default).Validate(value)
+		errs = append(errs, result.Errors...)
+	}
+	// the rules expect a value that complies with the schema
+	if len(errs) != 0 {
+		return errs
+	}
+	for _, rule := range validation.Rules {
+		if err := validateRule(rule, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func validateRule(rule kformv1alpha1.ValidationRule, value any) error {
+	env, err := cel.NewEnv(cel.Variable(keySelf, cel.DynType))
+	if err != nil {
+		return err
+	}
+	ast, iss := env.Compile(rule.Rule)
+	if iss.Err() != nil {
+		return fmt.Errorf("rule %q cannot be compiled, err: %s", rule.Rule, iss.Err().Error())
+	}
+	prog, err := env.Program(ast)
+	if err != nil {
+		return fmt.Errorf("rule %q cannot be compiled, err: %s", rule.Rule, err.Error())
+	}
+	val, _, err := prog.Eval(map[string]any{keySelf: value})
+	if err != nil {
+		return fmt.Errorf("rule %q cannot be evaluated, err: %s", rule.Rule, err.Error())
+	}
+	ok, isBool := val.Value().(bool)
+	if !isBool {
+		return fmt.Errorf("rule %q must evaluate to a bool, got: %v", rule.Rule, val.Value())
+	}
+	if !ok {
+		if rule.Message != "" {
+			return fmt.Errorf("%s", rule.Message)
+		}
+		return fmt.Errorf("failed rule: %s", rule.Rule)
+	}
+	return nil
+}
+
+func toJSON(value any) (any, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal value, err: %s", err.Error())
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal value, err: %s", err.Error())
+	}
+	return v, nil
+}
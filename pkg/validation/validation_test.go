@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	kformv1alpha1 "github.com/kform-dev/kform/apis/pkg/v1alpha1"
+)
+
+func TestValidate(t *testing.T) {
+	validation := `
+schema:
+  type: object
+  required: [data]
+  properties:
+    data:
+      type: object
+      required: [replicas]
+      properties:
+        replicas:
+          type: integer
+          minimum: 1
+rules:
+- rule: self.data.replicas < 10
+  message: replicas must be lower than 10
+`
+	tests := map[string]struct {
+		value          map[string]any
+		expectedErrors []string
+	}{
+		"Valid": {
+			value: map[string]any{"data": map[string]any{"replicas": 3}},
+		},
+		"MissingField": {
+			value:          map[string]any{"data": map[string]any{}},
+			expectedErrors: []string{"data.replicas in body is required"},
+		},
+		"WrongType": {
+			value:          map[string]any{"data": map[string]any{"replicas": "three"}},
+			expectedErrors: []string{"data.replicas in body must be of type integer"},
+		},
+		"FailedRule": {
+			value:          map[string]any{"data": map[string]any{"replicas": 12}},
+			expectedErrors: []string{"replicas must be lower than 10"},
+		},
+	}
+
+	v, err := kformv1alpha1.ParseValidation(validation)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := Validate(v, tc.value)
+			if len(errs) != len(tc.expectedErrors) {
+				t.Fatalf("want %d errors, got: %v", len(tc.expectedErrors), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.expectedErrors[i]) {
+					t.Errorf("want error %q, got: %s", tc.expectedErrors[i], err.Error())
+				}
+			}
+		})
+	}
+}